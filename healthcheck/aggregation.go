@@ -0,0 +1,227 @@
+package healthcheck
+
+// CheckResult is the status of a check provided to an AggregationPolicy. The status has already been limited according
+// to the criticality of the check, and forced by any check override.
+type CheckResult struct {
+	Name   string
+	Tags   []string
+	Status string
+}
+
+// AggregationPolicy combines the statuses of a set of checks into a single status, which must be one of
+// StatusOK, StatusWarning or StatusCritical. It is used for the app status and for the status sent to subscribers.
+// Informational checks never affect the app health, so they are not provided to the policy.
+type AggregationPolicy interface {
+	Aggregate(results []CheckResult) string
+}
+
+// AggregationPolicyFunc is an adapter to allow the use of an ordinary function as an AggregationPolicy
+type AggregationPolicyFunc func(results []CheckResult) string
+
+// GroupPolicy is an AggregationPolicy applied to a named group of checks by PerGroup.
+// The group contains the checks it lists by name, and the checks tagged with its name.
+type GroupPolicy struct {
+	Name   string
+	Checks []string
+	Policy AggregationPolicy
+}
+
+// worstOf is the AggregationPolicy returned by WorstOf
+type worstOf struct{}
+
+// quorum is the AggregationPolicy returned by Quorum
+type quorum struct {
+	k int
+}
+
+// weighted is the AggregationPolicy returned by Weighted
+type weighted struct {
+	weights           map[string]float64
+	warningThreshold  float64
+	criticalThreshold float64
+}
+
+// perGroup is the AggregationPolicy returned by PerGroup
+type perGroup struct {
+	groups  []GroupPolicy
+	groupOf map[string]int
+	named   map[string]int
+}
+
+// WithAggregationPolicy sets the policy used to combine the statuses of the checks into the app status,
+// and into the status sent to each subscriber. Defaults to WorstOf.
+func WithAggregationPolicy(policy AggregationPolicy) Option {
+	return func(hc *HealthCheck) {
+		hc.aggregationPolicy = policy
+	}
+}
+
+// getAggregationPolicy returns the aggregation policy of the health check, or WorstOf if none has been set
+func (hc *HealthCheck) getAggregationPolicy() AggregationPolicy {
+	if hc.aggregationPolicy == nil {
+		return worstOf{}
+	}
+	return hc.aggregationPolicy
+}
+
+// Aggregate calls f(results)
+func (f AggregationPolicyFunc) Aggregate(results []CheckResult) string {
+	return f(results)
+}
+
+// WorstOf returns a policy where the worst status wins, i.e. CRITICAL if any check is CRITICAL,
+// else WARNING if any check is WARNING, else OK. This is the default.
+func WorstOf() AggregationPolicy {
+	return worstOf{}
+}
+
+// Aggregate returns the worst of the provided statuses
+func (worstOf) Aggregate(results []CheckResult) string {
+	status := StatusOK
+	for _, result := range results {
+		if result.Status == StatusCritical {
+			return StatusCritical
+		} else if result.Status == StatusWarning {
+			status = StatusWarning
+		}
+	}
+	return status
+}
+
+// Quorum returns a policy for replicated dependencies that is CRITICAL only when fewer than k of the checks are
+// not CRITICAL, e.g. when 2 out of 3 replicas are needed. Otherwise it is WARNING if any check is not OK, else OK.
+// If fewer than k checks are provided, e.g. to a subscriber of some of them, all of them are needed.
+func Quorum(k int) AggregationPolicy {
+	return quorum{k: k}
+}
+
+// Aggregate returns the status of the provided checks given the quorum
+func (q quorum) Aggregate(results []CheckResult) string {
+	needed := q.k
+	if needed > len(results) {
+		needed = len(results)
+	}
+
+	available := 0
+	status := StatusOK
+	for _, result := range results {
+		if result.Status != StatusCritical {
+			available++
+		}
+		if result.Status != StatusOK {
+			status = StatusWarning
+		}
+	}
+
+	if available < needed {
+		return StatusCritical
+	}
+	return status
+}
+
+// Weighted returns a policy based on a score between 0 and 1, the weighted mean of the check scores, which are
+// 0 for OK, 0.5 for WARNING and 1 for CRITICAL. A check has a weight of 1 unless another one is provided for its name.
+// The policy is CRITICAL if the score reaches the critical threshold, else WARNING if it is above 0 and reaches
+// the warning threshold, else OK.
+func Weighted(weights map[string]float64, warningThreshold, criticalThreshold float64) AggregationPolicy {
+	return weighted{
+		weights:           weights,
+		warningThreshold:  warningThreshold,
+		criticalThreshold: criticalThreshold,
+	}
+}
+
+// Aggregate returns the status of the provided checks given their weighted score
+func (w weighted) Aggregate(results []CheckResult) string {
+	var total, score float64
+	for _, result := range results {
+		weight, ok := w.weights[result.Name]
+		if !ok {
+			weight = 1
+		}
+		total += weight
+
+		switch result.Status {
+		case StatusWarning:
+			score += weight / 2
+		case StatusCritical:
+			score += weight
+		}
+	}
+
+	if total <= 0 || score <= 0 {
+		return StatusOK
+	}
+	score /= total
+
+	if score >= w.criticalThreshold {
+		return StatusCritical
+	}
+	if score >= w.warningThreshold {
+		return StatusWarning
+	}
+	return StatusOK
+}
+
+// PerGroup returns a policy that aggregates the checks of each group with the policy of the group, or WorstOf if
+// it has none, then returns the worst of the group statuses and of the statuses of the checks not in any group.
+// A check is in the first group that lists its name or, if none does, in the first group named after one of its tags.
+// A group without any of the provided checks is ignored.
+func PerGroup(groups ...GroupPolicy) AggregationPolicy {
+	p := perGroup{
+		groups:  groups,
+		groupOf: make(map[string]int),
+		named:   make(map[string]int),
+	}
+	for i, group := range groups {
+		if _, ok := p.named[group.Name]; !ok {
+			p.named[group.Name] = i
+		}
+		for _, name := range group.Checks {
+			if _, ok := p.groupOf[name]; !ok {
+				p.groupOf[name] = i
+			}
+		}
+	}
+	return p
+}
+
+// Aggregate returns the worst of the group statuses and of the statuses of the checks not in any group
+func (p perGroup) Aggregate(results []CheckResult) string {
+	grouped := make([][]CheckResult, len(p.groups))
+	var statuses []CheckResult
+	for _, result := range results {
+		if i, ok := p.getGroup(result); ok {
+			grouped[i] = append(grouped[i], result)
+			continue
+		}
+		statuses = append(statuses, result)
+	}
+
+	for i, group := range p.groups {
+		if len(grouped[i]) == 0 {
+			continue
+		}
+		policy := group.Policy
+		if policy == nil {
+			policy = worstOf{}
+		}
+		statuses = append(statuses, CheckResult{Name: group.Name, Status: policy.Aggregate(grouped[i])})
+	}
+
+	return worstOf{}.Aggregate(statuses)
+}
+
+// getGroup returns the index of the group of the provided check, and false if it is not in any group
+func (p perGroup) getGroup(result CheckResult) (int, bool) {
+	if i, ok := p.groupOf[result.Name]; ok {
+		return i, true
+	}
+	group, found := 0, false
+	for _, tag := range result.Tags {
+		if i, ok := p.named[tag]; ok && (!found || i < group) {
+			group, found = i, true
+		}
+	}
+	return group, found
+}
@@ -0,0 +1,194 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck/mock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// results returns a check result for every provided status, named after its position
+func results(statuses ...string) []CheckResult {
+	var r []CheckResult
+	for i, status := range statuses {
+		r = append(r, CheckResult{Name: string(rune('a' + i)), Status: status})
+	}
+	return r
+}
+
+func TestWorstOf(t *testing.T) {
+	Convey("Given the worst-of policy", t, func() {
+		policy := WorstOf()
+
+		Convey("Then the worst status wins", func() {
+			So(policy.Aggregate(nil), ShouldEqual, StatusOK)
+			So(policy.Aggregate(results(StatusOK, StatusOK)), ShouldEqual, StatusOK)
+			So(policy.Aggregate(results(StatusOK, StatusWarning)), ShouldEqual, StatusWarning)
+			So(policy.Aggregate(results(StatusWarning, StatusCritical, StatusOK)), ShouldEqual, StatusCritical)
+		})
+	})
+}
+
+func TestQuorum(t *testing.T) {
+	Convey("Given a policy needing 2 out of 3 checks", t, func() {
+		policy := Quorum(2)
+
+		Convey("Then it is OK when all the checks are OK", func() {
+			So(policy.Aggregate(results(StatusOK, StatusOK, StatusOK)), ShouldEqual, StatusOK)
+		})
+
+		Convey("Then it is WARNING when one check is CRITICAL", func() {
+			So(policy.Aggregate(results(StatusOK, StatusCritical, StatusOK)), ShouldEqual, StatusWarning)
+		})
+
+		Convey("Then it is WARNING when checks are WARNING but not CRITICAL", func() {
+			So(policy.Aggregate(results(StatusWarning, StatusWarning, StatusWarning)), ShouldEqual, StatusWarning)
+		})
+
+		Convey("Then it is CRITICAL when two checks are CRITICAL", func() {
+			So(policy.Aggregate(results(StatusCritical, StatusOK, StatusCritical)), ShouldEqual, StatusCritical)
+		})
+
+		Convey("Then all the checks are needed when fewer than 2 are provided", func() {
+			So(policy.Aggregate(results(StatusOK)), ShouldEqual, StatusOK)
+			So(policy.Aggregate(results(StatusCritical)), ShouldEqual, StatusCritical)
+			So(policy.Aggregate(nil), ShouldEqual, StatusOK)
+		})
+	})
+}
+
+func TestWeighted(t *testing.T) {
+	Convey("Given a weighted policy where one check weighs as much as the three others", t, func() {
+		policy := Weighted(map[string]float64{"a": 3}, 0.25, 0.5)
+
+		Convey("Then it is OK when all the checks are OK", func() {
+			So(policy.Aggregate(results(StatusOK, StatusOK, StatusOK, StatusOK)), ShouldEqual, StatusOK)
+		})
+
+		Convey("Then it is OK when the score is below the warning threshold", func() {
+			So(policy.Aggregate(results(StatusOK, StatusCritical, StatusOK, StatusOK)), ShouldEqual, StatusOK)
+			So(policy.Aggregate(results(StatusOK, StatusWarning, StatusWarning, StatusOK)), ShouldEqual, StatusOK)
+		})
+
+		Convey("Then it is WARNING when the score reaches the warning threshold", func() {
+			So(policy.Aggregate(results(StatusOK, StatusCritical, StatusCritical, StatusOK)), ShouldEqual, StatusWarning)
+			So(policy.Aggregate(results(StatusWarning, StatusWarning, StatusOK, StatusOK)), ShouldEqual, StatusWarning)
+		})
+
+		Convey("Then it is CRITICAL when the score reaches the critical threshold", func() {
+			So(policy.Aggregate(results(StatusCritical, StatusOK, StatusOK, StatusOK)), ShouldEqual, StatusCritical)
+		})
+
+		Convey("Then it is OK without any checks", func() {
+			So(policy.Aggregate(nil), ShouldEqual, StatusOK)
+		})
+	})
+
+	Convey("Given a weighted policy with a warning threshold of 0", t, func() {
+		policy := Weighted(nil, 0, 1)
+
+		Convey("Then it is WARNING as soon as any check is not OK", func() {
+			So(policy.Aggregate(results(StatusOK, StatusOK)), ShouldEqual, StatusOK)
+			So(policy.Aggregate(results(StatusOK, StatusWarning)), ShouldEqual, StatusWarning)
+			So(policy.Aggregate(results(StatusCritical, StatusCritical)), ShouldEqual, StatusCritical)
+		})
+	})
+}
+
+func TestPerGroup(t *testing.T) {
+	Convey("Given a per-group policy with a quorum group and a worst-of group", t, func() {
+		policy := PerGroup(
+			GroupPolicy{Name: "elasticsearch", Checks: []string{"es-1", "es-2", "es-3"}, Policy: Quorum(2)},
+			GroupPolicy{Name: "storage", Checks: []string{"mongodb", "s3"}},
+		)
+		check := func(name, status string) CheckResult {
+			return CheckResult{Name: name, Status: status}
+		}
+
+		Convey("Then losing one node of the quorum group is only WARNING", func() {
+			So(policy.Aggregate([]CheckResult{
+				check("es-1", StatusCritical), check("es-2", StatusOK), check("es-3", StatusOK),
+				check("mongodb", StatusOK), check("s3", StatusOK),
+			}), ShouldEqual, StatusWarning)
+		})
+
+		Convey("Then losing two nodes of the quorum group is CRITICAL", func() {
+			So(policy.Aggregate([]CheckResult{
+				check("es-1", StatusCritical), check("es-2", StatusCritical), check("es-3", StatusOK),
+				check("mongodb", StatusOK), check("s3", StatusOK),
+			}), ShouldEqual, StatusCritical)
+		})
+
+		Convey("Then a group without a policy uses worst-of", func() {
+			So(policy.Aggregate([]CheckResult{
+				check("es-1", StatusOK), check("mongodb", StatusCritical), check("s3", StatusOK),
+			}), ShouldEqual, StatusCritical)
+		})
+
+		Convey("Then checks not in any group are considered individually", func() {
+			So(policy.Aggregate([]CheckResult{
+				check("es-1", StatusCritical), check("es-2", StatusOK), check("es-3", StatusOK),
+				check("kafka", StatusCritical),
+			}), ShouldEqual, StatusCritical)
+			So(policy.Aggregate([]CheckResult{check("kafka", StatusOK)}), ShouldEqual, StatusOK)
+		})
+	})
+}
+
+func TestWithAggregationPolicy(t *testing.T) {
+	t0 := time.Now().UTC()
+	t20 := t0.Add(-20 * time.Minute) // 20 min ago
+
+	okState := CheckState{name: "es-1", status: StatusOK, lastChecked: &t0, lastSuccess: &t0}
+	criticalState := CheckState{name: "es-2", status: StatusCritical, lastChecked: &t0, lastFailure: &t0, criticalSince: &t20}
+	otherOkState := CheckState{name: "es-3", status: StatusOK, lastChecked: &t0, lastSuccess: &t0}
+
+	Convey("Given a healthcheck with a quorum policy and one of three replicas CRITICAL", t, func() {
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		WithAggregationPolicy(Quorum(2))(&hc)
+		hc.Checks = createChecksSlice([]CheckState{okState, criticalState, otherOkState}, true)
+
+		Convey("Then the app status is WARNING", func() {
+			So(hc.getAppStatus(context.Background()), ShouldEqual, StatusWarning)
+		})
+
+		Convey("Then the status of a subscriber to two of the replicas uses the policy too", func() {
+			So(hc.getChecksStatus(hc.Checks[:2]), ShouldEqual, StatusCritical)
+			So(hc.getChecksStatus([]*Check{hc.Checks[0], hc.Checks[2]}), ShouldEqual, StatusOK)
+		})
+
+		Convey("Then a subscriber is notified with the status given by the policy", func() {
+			sub := &mock.SubscriberMock{OnHealthUpdateFunc: func(status string) {}}
+			hc.subscribers = map[Subscriber]map[*Check]struct{}{}
+			hc.Subscribe(sub, hc.Checks...)
+			hc.healthChangeCallback().Wait()
+			So(sub.OnHealthUpdateCalls(), ShouldHaveLength, 1)
+			So(sub.OnHealthUpdateCalls()[0].Status, ShouldEqual, StatusWarning)
+		})
+
+		Convey("Then an informational check is not provided to the policy", func() {
+			var provided []CheckResult
+			hc.aggregationPolicy = AggregationPolicyFunc(func(results []CheckResult) string {
+				provided = results
+				return StatusOK
+			})
+			hc.Checks[1].criticality = CriticalityInformational
+			So(hc.isAppHealthy(), ShouldEqual, StatusOK)
+			So(provided, ShouldResemble, []CheckResult{
+				{Name: "es-1", Status: StatusOK},
+				{Name: "es-3", Status: StatusOK},
+			})
+		})
+	})
+
+	Convey("Given a healthcheck without an aggregation policy", t, func() {
+		hc := &HealthCheck{statusLock: &sync.RWMutex{}}
+
+		Convey("Then worst-of is used", func() {
+			So(hc.getAggregationPolicy(), ShouldResemble, WorstOf())
+		})
+	})
+}
@@ -0,0 +1,172 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// healthResponse is a serialized response of Handler, which must not be modified once it has been created
+type healthResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// responseCache holds the serialized responses of Handler, which are reused until the health changes or they expire
+type responseCache struct {
+	maxAge     time.Duration
+	generation uint64
+	mutex      *sync.RWMutex
+	entries    map[responseCacheKey]*cachedResponse
+}
+
+// responseCacheKey identifies the cached response to a request
+type responseCacheKey struct {
+	format  ResponseFormat
+	verbose bool
+}
+
+// cachedResponse is a cached response, along with the generation of the health it describes and its expiry time
+type cachedResponse struct {
+	response   *healthResponse
+	generation uint64
+	expires    time.Time
+}
+
+// WithResponseCache caches the responses of Handler until the health changes or they are older than the provided maximum age
+func WithResponseCache(maxAge time.Duration) Option {
+	return func(hc *HealthCheck) {
+		if maxAge <= 0 {
+			hc.responseCache = nil
+			return
+		}
+		hc.responseCache = &responseCache{
+			maxAge:  maxAge,
+			mutex:   &sync.RWMutex{},
+			entries: map[responseCacheKey]*cachedResponse{},
+		}
+	}
+}
+
+// invalidateResponseCache makes the cached responses, if any, stale so that they are rebuilt on the next request
+func (hc *HealthCheck) invalidateResponseCache() {
+	if hc.responseCache != nil {
+		atomic.AddUint64(&hc.responseCache.generation, 1)
+	}
+}
+
+// get returns the cached response for the provided key, building it with the provided function if it is stale
+func (c *responseCache) get(key responseCacheKey, now time.Time, build func() (*healthResponse, error)) (*healthResponse, error) {
+	generation := atomic.LoadUint64(&c.generation)
+
+	c.mutex.RLock()
+	entry := c.entries[key]
+	c.mutex.RUnlock()
+	if entry.isFresh(generation, now) {
+		return entry.response, nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// the response may have been rebuilt by a concurrent request while waiting for the lock
+	if entry = c.entries[key]; entry.isFresh(generation, now) {
+		return entry.response, nil
+	}
+
+	resp, err := build()
+	if err != nil {
+		return nil, err
+	}
+	resp.header.Set("ETag", getETag(resp.body))
+	c.entries[key] = &cachedResponse{
+		response:   resp,
+		generation: generation,
+		expires:    now.Add(c.maxAge),
+	}
+	return resp, nil
+}
+
+// isFresh returns true if the cached response describes the provided generation of the health and has not expired
+func (e *cachedResponse) isFresh(generation uint64, now time.Time) bool {
+	return e != nil && e.generation == generation && now.Before(e.expires)
+}
+
+// newHealthResponse returns a new response with the provided content type, status code and headers, and the provided
+// body serialized as json. A 500 response without a body is returned if the body cannot be serialized.
+func newHealthResponse(ctx context.Context, contentType string, statusCode int, headers http.Header, body interface{}) *healthResponse {
+	resp := &healthResponse{
+		statusCode: statusCode,
+		header:     http.Header{},
+	}
+	for key, values := range headers {
+		resp.header[http.CanonicalHeaderKey(key)] = append([]string{}, values...)
+	}
+	resp.header.Set("Vary", "Accept")
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		log.Error(ctx, "failed to marshal json", err, log.Data{"health_check_response": body})
+		resp.statusCode = http.StatusInternalServerError
+		return resp
+	}
+
+	resp.header.Set("Content-Type", contentType)
+	resp.body = b
+	return resp
+}
+
+// write writes the response, or only its headers and status code for a HEAD request.
+// A 304 Not Modified response is written instead of a successful one if the request has a matching If-None-Match header.
+func (resp *healthResponse) write(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+	// the values are copied, as the response writer may modify them and the response may be cached
+	for key, values := range resp.header {
+		w.Header()[key] = append([]string{}, values...)
+	}
+
+	etag := resp.header.Get("ETag")
+	successful := resp.statusCode >= 200 && resp.statusCode < 300
+	if etag != "" && successful && etagMatches(req.Header.Get("If-None-Match"), etag) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(resp.statusCode)
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := w.Write(resp.body); err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err)
+		return
+	}
+}
+
+// getETag returns a strong entity tag for the provided body
+func getETag(body []byte) string {
+	h := fnv.New64a()
+	h.Write(body)
+	return `"` + strconv.FormatUint(h.Sum64(), 16) + `"`
+}
+
+// etagMatches returns true if the provided If-None-Match header matches the provided entity tag, using the weak
+// comparison defined for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, value := range strings.Split(ifNoneMatch, ",") {
+		value = strings.TrimSpace(value)
+		if value == "*" || strings.TrimPrefix(value, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
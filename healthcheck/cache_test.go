@@ -0,0 +1,199 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// offsetClock is a clock that is ahead of the system clock by a fixed offset
+type offsetClock struct {
+	realClock
+	offset time.Duration
+}
+
+func (c *offsetClock) Now() time.Time {
+	return time.Now().Add(c.offset)
+}
+
+func TestResponseCache(t *testing.T) {
+	t0 := time.Now().UTC()
+	t20 := t0.Add(-20 * time.Minute) // 20 min ago
+
+	callHandler := func(hc *HealthCheck, method, target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		hc.Handler(w, req)
+		return w
+	}
+
+	Convey("Given a healthcheck with an OK check and cached responses", t, func() {
+		clock := &offsetClock{}
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		hc.clock = clock
+		hc.Checks = createChecksSlice([]CheckState{
+			{name: "mongodb", status: StatusOK, lastChecked: &t0, lastSuccess: &t0},
+		}, true)
+		WithResponseCache(time.Minute)(&hc)
+
+		first := callHandler(&hc, http.MethodGet, "/health", "")
+
+		Convey("Then the response has an ETag", func() {
+			So(first.Code, ShouldEqual, http.StatusOK)
+			So(first.Header().Get("ETag"), ShouldNotBeEmpty)
+		})
+
+		Convey("When the state of the check is modified without a change notification", func() {
+			hc.Checks[0].state.message = "modified"
+			clock.offset = time.Second
+			second := callHandler(&hc, http.MethodGet, "/health", "")
+
+			Convey("Then the cached response is returned", func() {
+				So(second.Code, ShouldEqual, http.StatusOK)
+				So(second.Body.String(), ShouldEqual, first.Body.String())
+				So(second.Header().Get("ETag"), ShouldEqual, first.Header().Get("ETag"))
+			})
+
+			Convey("Then a filtered request is not served from the cache", func() {
+				w := callHandler(&hc, http.MethodGet, "/health?check=mongodb", "")
+				So(w.Body.String(), ShouldContainSubstring, "modified")
+				So(w.Header().Get("ETag"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the health changes", func() {
+			hc.Checks[0].state.message = "modified"
+			hc.notifyHealthChange(hc.Checks[0]).Wait()
+			second := callHandler(&hc, http.MethodGet, "/health", "")
+
+			Convey("Then the response is rebuilt, with a new ETag", func() {
+				So(second.Body.String(), ShouldContainSubstring, "modified")
+				So(second.Header().Get("ETag"), ShouldNotEqual, first.Header().Get("ETag"))
+			})
+		})
+
+		Convey("When the cached response has expired", func() {
+			hc.Checks[0].state.message = "modified"
+			clock.offset = 2 * time.Minute
+			second := callHandler(&hc, http.MethodGet, "/health", "")
+
+			Convey("Then the response is rebuilt", func() {
+				So(second.Body.String(), ShouldContainSubstring, "modified")
+				So(second.Header().Get("ETag"), ShouldNotEqual, first.Header().Get("ETag"))
+			})
+		})
+
+		Convey("When the handler is called with a matching If-None-Match header", func() {
+			w := callHandler(&hc, http.MethodGet, "/health", `"other", `+first.Header().Get("ETag"))
+
+			Convey("Then a 304 is returned without a body", func() {
+				So(w.Code, ShouldEqual, http.StatusNotModified)
+				So(w.Body.Len(), ShouldEqual, 0)
+				So(w.Header().Get("ETag"), ShouldEqual, first.Header().Get("ETag"))
+			})
+		})
+
+		Convey("When the handler is called with a weak matching If-None-Match header", func() {
+			w := callHandler(&hc, http.MethodGet, "/health", "W/"+first.Header().Get("ETag"))
+
+			Convey("Then a 304 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotModified)
+			})
+		})
+
+		Convey("When the handler is called with a different If-None-Match header", func() {
+			w := callHandler(&hc, http.MethodGet, "/health", `"other"`)
+
+			Convey("Then the full response is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, first.Body.String())
+			})
+		})
+
+		Convey("When the handler is called with a HEAD request", func() {
+			w := callHandler(&hc, http.MethodHead, "/health", "")
+
+			Convey("Then only the status code and headers are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("ETag"), ShouldEqual, first.Header().Get("ETag"))
+				So(w.Body.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the headers of a cached response are modified after it has been written", func() {
+			etag := first.Header().Get("ETag")
+			first.Header()["Etag"][0] = `"modified"`
+			second := callHandler(&hc, http.MethodGet, "/health", "")
+
+			Convey("Then the cached response is not modified", func() {
+				So(second.Header().Get("ETag"), ShouldEqual, etag)
+			})
+		})
+
+		Convey("When the response is requested without the checks", func() {
+			w := callHandler(&hc, http.MethodGet, "/health?verbose=false", "")
+
+			Convey("Then it is cached separately", func() {
+				So(w.Body.String(), ShouldEqual, `{"status":"OK"}`)
+				So(w.Header().Get("ETag"), ShouldNotEqual, first.Header().Get("ETag"))
+			})
+		})
+	})
+
+	Convey("Given a healthcheck with a CRITICAL check and cached responses", t, func() {
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{
+			{name: "kafka", status: StatusCritical, lastChecked: &t0, lastFailure: &t0, criticalSince: &t20},
+		}, true)
+		WithResponseCache(time.Minute)(&hc)
+
+		first := callHandler(&hc, http.MethodGet, "/health", "")
+
+		Convey("When the handler is called with a matching If-None-Match header", func() {
+			w := callHandler(&hc, http.MethodGet, "/health", first.Header().Get("ETag"))
+
+			Convey("Then the full response is still returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(w.Body.String(), ShouldEqual, first.Body.String())
+			})
+		})
+	})
+
+	Convey("Given a healthcheck with cached responses and a check that has a critical timeout", t, func() {
+		hc := New(version, criticalTimeout, interval, WithResponseCache(time.Minute))
+		c, err := hc.AddAndGetCheck("kafka", func(ctx context.Context, state *CheckState) error { return nil }, WithCriticalTimeout(100*time.Millisecond))
+		So(err, ShouldBeNil)
+		hc.StartTime = t20
+		So(c.state.Update(StatusCritical, "unreachable", 0), ShouldBeNil)
+
+		first := callHandler(&hc, http.MethodGet, "/health", "")
+		So(first.Code, ShouldEqual, http.StatusTooManyRequests)
+
+		Convey("When the critical timeout of the check expires", func() {
+			time.Sleep(200 * time.Millisecond)
+			w := callHandler(&hc, http.MethodGet, "/health", first.Header().Get("ETag"))
+
+			Convey("Then the cached response is invalidated and the escalation is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(w.Header().Get("ETag"), ShouldNotEqual, first.Header().Get("ETag"))
+			})
+		})
+	})
+
+	Convey("Given a healthcheck without cached responses", t, func() {
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		WithResponseCache(0)(&hc)
+
+		Convey("Then the responses do not have an ETag", func() {
+			So(hc.responseCache, ShouldBeNil)
+			So(callHandler(&hc, http.MethodGet, "/health", "").Header().Get("ETag"), ShouldBeEmpty)
+		})
+	})
+}
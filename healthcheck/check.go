@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,6 +17,19 @@ const (
 	StatusCritical = "CRITICAL"
 )
 
+// Criticality defines how much a check is allowed to affect the overall health of the app
+type Criticality int
+
+// A list of possible check criticalities
+const (
+	// CriticalityCritical checks can take the app to CRITICAL. This is the default.
+	CriticalityCritical Criticality = iota
+	// CriticalityDegrading checks can take the app to WARNING at most
+	CriticalityDegrading
+	// CriticalityInformational checks are reported but do not affect the app health
+	CriticalityInformational
+)
+
 // Checker represents the interface all checker functions abide to
 type Checker func(context.Context, *CheckState) error
 
@@ -28,6 +42,13 @@ type CheckState struct {
 	lastChecked    *time.Time
 	lastSuccess    *time.Time
 	lastFailure    *time.Time
+	criticalSince  *time.Time
+	dependency     *Dependency
+	history        *history
+	historySize    int
+	damping        *damping
+	override       *Override
+	clock          Clock
 	mutex          *sync.RWMutex
 	changeCallback func() *sync.WaitGroup
 }
@@ -41,12 +62,64 @@ type checkStateJSON struct {
 	LastChecked *time.Time `json:"last_checked"`
 	LastSuccess *time.Time `json:"last_success"`
 	LastFailure *time.Time `json:"last_failure"`
+	Override    *Override  `json:"override,omitempty"`
 }
 
 // Check represents a check performed by the health check
 type Check struct {
-	state   *CheckState
-	checker Checker
+	state           *CheckState
+	checker         Checker
+	interval        time.Duration
+	timeout         time.Duration
+	initialDelay    time.Duration
+	criticality     Criticality
+	criticalTimeout time.Duration
+	tags            []string
+	probes          Probe
+	metrics         *checkMetrics
+	// abandoned is set while a checker that exceeded its timeout has not returned yet
+	abandoned atomic.Bool
+}
+
+// CheckOption configures how an individual check is run
+type CheckOption func(*Check)
+
+// WithInterval overrides the health check interval for this check
+func WithInterval(interval time.Duration) CheckOption {
+	return func(c *Check) {
+		c.interval = interval
+	}
+}
+
+// WithTimeout sets the maximum time the checker is allowed to run for.
+// A checker that exceeds it has its context cancelled and the check is recorded as CRITICAL straight away,
+// even if the checker ignores its context, in which case it is left to return in the background and the check is not run again until it does.
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(c *Check) {
+		c.timeout = timeout
+	}
+}
+
+// WithInitialDelay delays the first run of the checker after the health check has been started
+func WithInitialDelay(delay time.Duration) CheckOption {
+	return func(c *Check) {
+		c.initialDelay = delay
+	}
+}
+
+// WithCriticality sets how much the check is allowed to affect the overall health of the app
+func WithCriticality(criticality Criticality) CheckOption {
+	return func(c *Check) {
+		c.criticality = criticality
+	}
+}
+
+// WithCriticalTimeout overrides the critical error timeout of the health check for this check,
+// i.e. how long the check has to be CRITICAL for before it takes the app to CRITICAL
+func WithCriticalTimeout(timeout time.Duration) CheckOption {
+	return func(c *Check) {
+		c.criticalTimeout = timeout
+	}
 }
 
 // Name gets the check name
@@ -120,21 +193,54 @@ func (s *CheckState) LastFailure() *time.Time {
 	return &t
 }
 
+// CriticalSince gets the time at which the check first reported CRITICAL since it last reported OK,
+// or nil if it is not CRITICAL
+func (s *CheckState) CriticalSince() *time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.criticalSince == nil {
+		return nil
+	}
+
+	t := *s.criticalSince
+	return &t
+}
+
+// Dependency gets the health check reported by the downstream app, if the checker has set one
+func (s *CheckState) Dependency() *Dependency {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.dependency
+}
+
+// SetDependency sets the health check reported by the downstream app that is being checked,
+// which is nested in the health check of this app. Provide nil to remove it.
+// The dependency must not be modified after it has been set.
+func (s *CheckState) SetDependency(dependency *Dependency) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.dependency = dependency
+}
+
 // Update updates the relevant state fields based on the status provided
 // status of the check, must be one of healthcheck.StatusOK, healthcheck.StatusWarning or healthcheck.StatusCritical
 // message briefly describing the check state
 // statusCode returned if the check was an HTTP check (optional, provide 0 if not relevant)
 // If any Subscriber is registered, the callback will be triggered if the state changed since last interation
 func (s *CheckState) Update(status, message string, statusCode int) error {
-	now := time.Now().UTC()
 	stateChanged := false
 
 	s.mutex.Lock()
+	now := s.getClock().Now().UTC()
+	changeCallback := s.changeCallback
 	defer func() {
 		s.mutex.Unlock()
 		// the callback needs to be triggered after unlocking in order to prevent having a deadlock
-		if stateChanged && s.changeCallback != nil {
-			s.changeCallback()
+		if stateChanged && changeCallback != nil {
+			changeCallback()
 		}
 	}()
 
@@ -147,34 +253,107 @@ func (s *CheckState) Update(status, message string, statusCode int) error {
 		return fmt.Errorf("invalid check status, must be one of %s, %s or %s", StatusOK, StatusWarning, StatusCritical)
 	}
 
+	s.lastChecked = &now
+
+	if s.damping != nil {
+		var apply bool
+		if status, message, statusCode, apply = s.damping.apply(now, s.status, status, message, statusCode); !apply {
+			return nil
+		}
+	}
+
 	if s.status != status {
 		stateChanged = true
+		s.addHistoryEntry(now, status, message, statusCode)
+	}
+
+	// a WARNING in between CRITICAL results does not reset the time of the first critical error, only a success does
+	switch status {
+	case StatusOK:
+		s.criticalSince = nil
+	case StatusCritical:
+		if s.criticalSince == nil {
+			s.criticalSince = &now
+		}
 	}
 
 	s.status = status
 	s.message = message
 	s.statusCode = statusCode
-	s.lastChecked = &now
 
 	return nil
 }
 
+// addHistoryEntry records a transition to the provided status in the history of the check.
+// The caller must hold the mutex.
+func (s *CheckState) addHistoryEntry(now time.Time, status, message string, statusCode int) {
+	if s.history == nil {
+		size := s.historySize
+		if size <= 0 {
+			size = defaultHistorySize
+		}
+		s.history = newHistory(size)
+	}
+
+	s.history.add(HistoryEntry{
+		Time:       now,
+		OldStatus:  s.status,
+		NewStatus:  status,
+		Message:    message,
+		StatusCode: statusCode,
+	})
+}
+
+// getClock returns the clock of the check, or the system clock if none has been set.
+// The caller must hold the mutex.
+func (s *CheckState) getClock() Clock {
+	if s.clock == nil {
+		return realClock{}
+	}
+	return s.clock
+}
+
+// setChangeCallback sets the callback that is triggered when the state changes. Provide nil to remove it.
+func (s *CheckState) setChangeCallback(changeCallback func() *sync.WaitGroup) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.changeCallback = changeCallback
+}
+
+// setRunDuration sets the duration of the transitions recorded since the provided start of a run of the checker,
+// that do not have a duration yet, as the time from the start of the run to the transition
+func (s *CheckState) setRunDuration(start time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.history != nil {
+		s.history.setDuration(start)
+	}
+}
+
 // hasRun returns true if the check has been run and has state
 func (c *Check) hasRun() bool {
 	return c.state.LastChecked() != nil
 }
 
 // NewCheck returns a pointer to a new instantiated Check with
-// the provided checker function
-func NewCheck(name string, checker Checker) (*Check, error) {
+// the provided checker function and any check options
+func NewCheck(name string, checker Checker, opts ...CheckOption) (*Check, error) {
 	if checker == nil {
 		return nil, errors.New("expected checker but none provided")
 	}
 
-	return &Check{
+	check := &Check{
 		state:   NewCheckState(name),
 		checker: checker,
-	}, nil
+		metrics: newCheckMetrics(),
+	}
+	for _, opt := range opts {
+		opt(check)
+	}
+
+	return check, nil
 }
 
 // NewCheckState returns a pointer to a new instantiated CheckState
@@ -195,14 +374,21 @@ func (s *CheckState) MarshalJSON() ([]byte, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
+	// the status forced by an override is reported instead of the status of the checker
+	status := s.status
+	if s.override != nil {
+		status = s.override.Status
+	}
+
 	return json.Marshal(checkStateJSON{
 		Name:        s.name,
-		Status:      s.status,
+		Status:      status,
 		StatusCode:  s.statusCode,
 		Message:     s.message,
 		LastChecked: s.lastChecked,
 		LastSuccess: s.lastSuccess,
 		LastFailure: s.lastFailure,
+		Override:    s.override,
 	})
 }
 
@@ -233,6 +419,7 @@ func (s *CheckState) UnmarshalJSON(b []byte) error {
 		s.lastChecked = temp.LastChecked
 		s.lastSuccess = temp.LastSuccess
 		s.lastFailure = temp.LastFailure
+		s.override = temp.Override
 	}
 	return err
 }
@@ -44,6 +44,41 @@ func TestCreateNew(t *testing.T) {
 	})
 }
 
+func TestCreateNewWithOptions(t *testing.T) {
+	checkerFunc := func(ctx context.Context, check *CheckState) error {
+		return nil
+	}
+
+	Convey("Given a new check created with check options", t, func() {
+		check, err := NewCheck("check", checkerFunc,
+			WithInterval(time.Minute),
+			WithTimeout(5*time.Second),
+			WithInitialDelay(10*time.Second),
+			WithCriticalTimeout(30*time.Second),
+		)
+		So(err, ShouldBeNil)
+
+		Convey("Then the options are applied to the check", func() {
+			So(check.interval, ShouldEqual, time.Minute)
+			So(check.timeout, ShouldEqual, 5*time.Second)
+			So(check.initialDelay, ShouldEqual, 10*time.Second)
+			So(check.criticalTimeout, ShouldEqual, 30*time.Second)
+		})
+	})
+
+	Convey("Given a new check created without check options", t, func() {
+		check, err := NewCheck("check", checkerFunc)
+		So(err, ShouldBeNil)
+
+		Convey("Then the check has no overrides", func() {
+			So(check.interval, ShouldEqual, 0)
+			So(check.timeout, ShouldEqual, 0)
+			So(check.initialDelay, ShouldEqual, 0)
+			So(check.criticalTimeout, ShouldEqual, 0)
+		})
+	})
+}
+
 func TestUpdate(t *testing.T) {
 	var (
 		checkName   = "check name"
@@ -248,6 +283,46 @@ func TestUpdate(t *testing.T) {
 	})
 }
 
+func TestUpdateCriticalSince(t *testing.T) {
+	var (
+		checkName   = "check name"
+		okMessage   = "I'm OK"
+		warnMessage = "degraded"
+		failMessage = "failed to ..."
+	)
+
+	Convey("Given a new check state", t, func() {
+		state := NewCheckState(checkName)
+		So(state.CriticalSince(), ShouldBeNil)
+
+		Convey("When the state is updated with critical status", func() {
+			So(state.Update(StatusCritical, failMessage, 500), ShouldBeNil)
+			criticalSince := state.CriticalSince()
+			So(criticalSince, ShouldResemble, state.LastFailure())
+
+			Convey("Then the time of the first critical error is kept by further critical and warning updates", func() {
+				So(state.Update(StatusCritical, failMessage, 500), ShouldBeNil)
+				So(state.Update(StatusWarning, warnMessage, 429), ShouldBeNil)
+				So(state.Update(StatusCritical, failMessage, 500), ShouldBeNil)
+				So(state.CriticalSince(), ShouldResemble, criticalSince)
+			})
+
+			Convey("Then the time of the first critical error is reset by an OK update", func() {
+				So(state.Update(StatusOK, okMessage, 200), ShouldBeNil)
+				So(state.CriticalSince(), ShouldBeNil)
+			})
+		})
+
+		Convey("When the state is updated with warning status", func() {
+			So(state.Update(StatusWarning, warnMessage, 429), ShouldBeNil)
+
+			Convey("Then there is no time of first critical error", func() {
+				So(state.CriticalSince(), ShouldBeNil)
+			})
+		})
+	})
+}
+
 func TestGets(t *testing.T) {
 	Convey("Given a populated check state", t, func() {
 		t0 := time.Unix(0, 0).UTC()
@@ -0,0 +1,196 @@
+// Package http provides a health checker for dependencies that are reached over HTTP
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+// maxBodySize is the maximum number of bytes read from a response body
+const maxBodySize = 1 << 20
+
+// Client represents the HTTP client used to call the dependency
+type Client interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// BodyMatcher validates a response body, returning an error describing why it does not match
+type BodyMatcher func(body []byte) error
+
+// Checker calls an HTTP endpoint and updates the check state according to the response
+type Checker struct {
+	url                 string
+	method              string
+	headers             http.Header
+	expectedStatusCodes []int
+	bodyMatcher         BodyMatcher
+	client              Client
+	dependencyDepth     int
+}
+
+// Option configures a Checker
+type Option func(*Checker)
+
+// WithMethod sets the HTTP method used to call the endpoint. Defaults to GET.
+func WithMethod(method string) Option {
+	return func(c *Checker) {
+		c.method = method
+	}
+}
+
+// WithHeader adds a header to the request sent to the endpoint
+func WithHeader(key, value string) Option {
+	return func(c *Checker) {
+		c.headers.Add(key, value)
+	}
+}
+
+// WithExpectedStatusCodes sets the status codes that are considered healthy. Defaults to any 2xx status code.
+func WithExpectedStatusCodes(codes ...int) Option {
+	return func(c *Checker) {
+		c.expectedStatusCodes = codes
+	}
+}
+
+// WithBodyMatcher sets a matcher that the response body must satisfy for the check to be healthy
+func WithBodyMatcher(matcher BodyMatcher) Option {
+	return func(c *Checker) {
+		c.bodyMatcher = matcher
+	}
+}
+
+// WithClient sets the HTTP client used to call the endpoint. Defaults to http.DefaultClient.
+func WithClient(client Client) Option {
+	return func(c *Checker) {
+		c.client = client
+	}
+}
+
+// WithDependencies nests the dp-healthcheck response of the dependency in the health check of this app,
+// including the dependencies it reports itself, up to maxDepth levels below this app
+func WithDependencies(maxDepth int) Option {
+	return func(c *Checker) {
+		c.dependencyDepth = maxDepth
+	}
+}
+
+// BodyContains returns a BodyMatcher that requires the response body to contain the provided string
+func BodyContains(s string) BodyMatcher {
+	return func(body []byte) error {
+		if !bytes.Contains(body, []byte(s)) {
+			return fmt.Errorf("response body does not contain %q", s)
+		}
+		return nil
+	}
+}
+
+// BodyMatchesRegexp returns a BodyMatcher that requires the response body to match the provided regular expression
+func BodyMatchesRegexp(re *regexp.Regexp) BodyMatcher {
+	return func(body []byte) error {
+		if !re.Match(body) {
+			return fmt.Errorf("response body does not match %q", re.String())
+		}
+		return nil
+	}
+}
+
+// NewChecker returns a new Checker that calls the provided URL
+func NewChecker(url string, opts ...Option) *Checker {
+	c := &Checker{
+		url:     url,
+		method:  http.MethodGet,
+		headers: http.Header{},
+		client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Check calls the endpoint and updates the provided check state.
+// If the endpoint responds with a dp-healthcheck response, its status is used for the check state,
+// otherwise the state is OK if the response has an expected status code and matches the body matcher, if any.
+func (c *Checker) Check(ctx context.Context, state *healthcheck.CheckState) error {
+	req, err := http.NewRequestWithContext(ctx, c.method, c.url, http.NoBody)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	// the dependency is replaced on every run, so that it is removed if the dependency no longer reports one
+	var dependency *healthcheck.Dependency
+	if c.dependencyDepth > 0 {
+		defer func() {
+			state.SetDependency(dependency)
+		}()
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return state.Update(healthcheck.StatusCritical, fmt.Sprintf("failed to call %s: %s", c.url, err), 0)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return state.Update(healthcheck.StatusCritical, fmt.Sprintf("failed to read response body from %s: %s", c.url, err), resp.StatusCode)
+	}
+
+	if c.bodyMatcher != nil {
+		if err := c.bodyMatcher(body); err != nil {
+			return state.Update(healthcheck.StatusCritical, fmt.Sprintf("unexpected response from %s: %s", c.url, err), resp.StatusCode)
+		}
+	}
+
+	if hc, ok := getHealthCheck(body); ok {
+		if c.dependencyDepth > 0 {
+			dependency = healthcheck.NewDependency(c.url, hc, c.dependencyDepth)
+		}
+		return state.Update(hc.Status, fmt.Sprintf("%s reported status %s", c.url, hc.Status), resp.StatusCode)
+	}
+
+	if !c.isExpectedStatusCode(resp.StatusCode) {
+		return state.Update(healthcheck.StatusCritical, fmt.Sprintf("%s responded with unexpected status code %d", c.url, resp.StatusCode), resp.StatusCode)
+	}
+
+	return state.Update(healthcheck.StatusOK, fmt.Sprintf("%s is ok", c.url), resp.StatusCode)
+}
+
+// isExpectedStatusCode returns true if the provided status code is one of the expected status codes
+func (c *Checker) isExpectedStatusCode(code int) bool {
+	if len(c.expectedStatusCodes) == 0 {
+		return code >= http.StatusOK && code < http.StatusMultipleChoices
+	}
+	for _, expected := range c.expectedStatusCodes {
+		if code == expected {
+			return true
+		}
+	}
+	return false
+}
+
+// getHealthCheck returns the health check in the provided body if it is a dp-healthcheck response
+func getHealthCheck(body []byte) (healthcheck.HealthCheck, bool) {
+	var hc healthcheck.HealthCheck
+	if err := json.Unmarshal(body, &hc); err != nil {
+		return hc, false
+	}
+	switch hc.Status {
+	case healthcheck.StatusOK, healthcheck.StatusWarning, healthcheck.StatusCritical:
+		return hc, true
+	default:
+		return hc, false
+	}
+}
@@ -0,0 +1,236 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTestServer(code int, body string, assertRequest func(req *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if assertRequest != nil {
+			assertRequest(req)
+		}
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a dependency that responds with 200", t, func() {
+		var receivedReq *http.Request
+		srv := newTestServer(http.StatusOK, "all good", func(req *http.Request) {
+			receivedReq = req
+		})
+		defer srv.Close()
+
+		Convey("When the checker is run with the default options", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then a GET request is sent and the state is OK", func() {
+				So(receivedReq.Method, ShouldEqual, http.MethodGet)
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.StatusCode(), ShouldEqual, http.StatusOK)
+				So(state.Message(), ShouldEqual, srv.URL+" is ok")
+			})
+		})
+
+		Convey("When the checker is run with a method and headers", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL, WithMethod(http.MethodHead), WithHeader("Authorization", "Bearer token")).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the request is sent with the method and headers", func() {
+				So(receivedReq.Method, ShouldEqual, http.MethodHead)
+				So(receivedReq.Header.Get("Authorization"), ShouldEqual, "Bearer token")
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+			})
+		})
+
+		Convey("When the checker is run with a body matcher that matches", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL, WithBodyMatcher(BodyMatchesRegexp(regexp.MustCompile("^all")))).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+			})
+		})
+
+		Convey("When the checker is run with a body matcher that does not match", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL, WithBodyMatcher(BodyContains("ready"))).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the state is CRITICAL", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.StatusCode(), ShouldEqual, http.StatusOK)
+				So(state.Message(), ShouldEqual, "unexpected response from "+srv.URL+`: response body does not contain "ready"`)
+			})
+		})
+
+		Convey("When the checker is run expecting a different status code", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL, WithExpectedStatusCodes(http.StatusNoContent)).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the state is CRITICAL", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.Message(), ShouldEqual, srv.URL+" responded with unexpected status code 200")
+			})
+		})
+	})
+
+	Convey("Given a dependency that responds with 503", t, func() {
+		srv := newTestServer(http.StatusServiceUnavailable, "", nil)
+		defer srv.Close()
+
+		Convey("When the checker is run with the default options", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the state is CRITICAL", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.StatusCode(), ShouldEqual, http.StatusServiceUnavailable)
+			})
+		})
+
+		Convey("When the checker is run expecting that status code", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL, WithExpectedStatusCodes(http.StatusOK, http.StatusServiceUnavailable)).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+			})
+		})
+	})
+
+	Convey("Given a dependency that responds with a dp-healthcheck WARNING response", t, func() {
+		srv := newTestServer(http.StatusTooManyRequests, `{"status":"WARNING","checks":[{"name":"mongodb","status":"WARNING"}]}`, nil)
+		defer srv.Close()
+
+		Convey("When the checker is run", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the status of the dependency is passed through", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.StatusCode(), ShouldEqual, http.StatusTooManyRequests)
+				So(state.Message(), ShouldEqual, srv.URL+" reported status WARNING")
+			})
+		})
+	})
+
+	Convey("Given a dependency that responds with a dp-healthcheck CRITICAL response", t, func() {
+		srv := newTestServer(http.StatusInternalServerError, `{"status":"CRITICAL"}`, nil)
+		defer srv.Close()
+
+		Convey("When the checker is run", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the status of the dependency is passed through", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.StatusCode(), ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+	})
+
+	Convey("Given a dependency that responds with JSON that is not a dp-healthcheck response", t, func() {
+		srv := newTestServer(http.StatusOK, `{"status":"up"}`, nil)
+		defer srv.Close()
+
+		Convey("When the checker is run", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the status code is used to determine the state", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+			})
+		})
+	})
+
+	Convey("Given a dependency that cannot be reached", t, func() {
+		srv := newTestServer(http.StatusOK, "", nil)
+		srv.Close()
+
+		Convey("When the checker is run", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the state is CRITICAL without a status code", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.StatusCode(), ShouldEqual, 0)
+				So(state.Message(), ShouldStartWith, "failed to call "+srv.URL)
+			})
+		})
+	})
+
+	Convey("Given an invalid URL", t, func() {
+		Convey("When the checker is run", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker("http://invalid\x7f").Check(ctx, state)
+
+			Convey("Then an error is returned and the state is not updated", func() {
+				So(err, ShouldNotBeNil)
+				So(state.Status(), ShouldEqual, "")
+			})
+		})
+	})
+
+	Convey("Given a dependency that responds with a dp-healthcheck response that includes its own dependencies", t, func() {
+		srv := newTestServer(http.StatusOK, `{"status":"OK","checks":[{"name":"zebedee","status":"OK"}],"dependencies":{"zebedee":{"url":"http://zebedee/health","status":"OK","checks":[]}}}`, nil)
+		defer srv.Close()
+
+		Convey("When the checker is run with dependencies enabled", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL, WithDependencies(2)).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the dependency tree is set on the check state", func() {
+				dependency := state.Dependency()
+				So(dependency, ShouldNotBeNil)
+				So(dependency.URL, ShouldEqual, srv.URL)
+				So(dependency.Status, ShouldEqual, healthcheck.StatusOK)
+				So(dependency.Checks, ShouldHaveLength, 1)
+				So(dependency.Dependencies, ShouldContainKey, "zebedee")
+				So(dependency.Dependencies["zebedee"].URL, ShouldEqual, "http://zebedee/health")
+			})
+
+			Convey("When the dependency stops responding with a dp-healthcheck response", func() {
+				srv.Close()
+				err := NewChecker(srv.URL, WithDependencies(2)).Check(ctx, state)
+				So(err, ShouldBeNil)
+
+				Convey("Then the dependency tree is removed from the check state", func() {
+					So(state.Dependency(), ShouldBeNil)
+				})
+			})
+		})
+
+		Convey("When the checker is run without dependencies enabled", func() {
+			state := healthcheck.NewCheckState("dependency")
+			err := NewChecker(srv.URL).Check(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then no dependency tree is set on the check state", func() {
+				So(state.Dependency(), ShouldBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,180 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+// cgroupRoot is the directory where the cgroup hierarchies are mounted
+const cgroupRoot = "/sys/fs/cgroup"
+
+// procCgroup is the file listing the cgroups of the app, relative to the root of their hierarchy
+const procCgroup = "/proc/self/cgroup"
+
+// cgroupV1Unlimited is the memory limit above which a cgroup v1 is considered to have no limit,
+// as its limit is then set to the largest page aligned value instead
+const cgroupV1Unlimited = 1 << 62
+
+// cgroupMemory is the memory usage and limit of a cgroup, in bytes. The limit is 0 if the cgroup has no limit.
+type cgroupMemory struct {
+	usage uint64
+	limit uint64
+}
+
+// CgroupMemoryChecker returns a checker of the ratio, between 0 and 1, of the memory used by the cgroup of the app,
+// e.g. its container, to the memory limit of the cgroup against the provided thresholds. The cgroup of the app is read
+// from /proc/self/cgroup, and looked up under /sys/fs/cgroup. Both cgroup v1 and v2 are
+// supported. As the kernel reclaims the inactive page cache before reaching the limit, it is not counted as used.
+// The state is OK if the cgroup has no memory limit.
+func CgroupMemoryChecker(warning, critical float64) healthcheck.Checker {
+	return cgroupMemoryChecker(cgroupRoot, procCgroup, warning, critical)
+}
+
+func cgroupMemoryChecker(root, procPath string, warning, critical float64) healthcheck.Checker {
+	return func(ctx context.Context, state *healthcheck.CheckState) error {
+		memory, err := readCgroupMemory(root, procPath)
+		if err != nil {
+			return state.Update(healthcheck.StatusWarning, fmt.Sprintf("failed to read the cgroup memory: %s", err), 0)
+		}
+
+		if memory.limit == 0 {
+			return state.Update(healthcheck.StatusOK, fmt.Sprintf("cgroup memory usage is %s, without a limit", formatBytes(memory.usage)), 0)
+		}
+
+		ratio := float64(memory.usage) / float64(memory.limit)
+		status := getStatus(ratio, warning, critical)
+		message := withThreshold(fmt.Sprintf("cgroup memory usage is %s of %s (%s)", formatBytes(memory.usage), formatBytes(memory.limit), formatPercent(ratio)), status, formatPercent(warning), formatPercent(critical))
+		return state.Update(status, message, 0)
+	}
+}
+
+// readCgroupMemory returns the memory usage and limit of the cgroup of the app, listed in the provided /proc/self/cgroup
+// file, under the hierarchies mounted in the provided directory, with the files of cgroup v2 if they exist,
+// or else with the ones of cgroup v1
+func readCgroupMemory(root, procPath string) (cgroupMemory, error) {
+	v2Dir, v1Dir := getCgroupDirs(root, procPath)
+	if _, err := os.Stat(filepath.Join(v2Dir, "memory.current")); err == nil {
+		return readCgroupV2Memory(v2Dir)
+	}
+	return readCgroupV1Memory(v1Dir)
+}
+
+// getCgroupDirs returns the directories of the cgroup v2 and of the cgroup v1 memory controller of the app, from the
+// provided /proc/self/cgroup file and the directory where the hierarchies are mounted. The root of a hierarchy is
+// returned if the cgroup of the app cannot be found in it, e.g. in a container that only has its own cgroup mounted.
+func getCgroupDirs(root, procPath string) (v2Dir, v1Dir string) {
+	v2Dir, v1Dir = root, filepath.Join(root, "memory")
+
+	b, err := os.ReadFile(procPath)
+	if err != nil {
+		return v2Dir, v1Dir
+	}
+
+	// every line is made of the hierarchy ID, the controllers and the path of the cgroup, e.g. 0::/system.slice/app.service
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		switch {
+		case fields[0] == "0" && fields[1] == "":
+			v2Dir = getExistingDir(filepath.Join(root, fields[2]), v2Dir)
+		case slices.Contains(strings.Split(fields[1], ","), "memory"):
+			v1Dir = getExistingDir(filepath.Join(root, "memory", fields[2]), v1Dir)
+		}
+	}
+	return v2Dir, v1Dir
+}
+
+// getExistingDir returns the provided directory if it exists, or else the fallback one
+func getExistingDir(dir, fallback string) string {
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		return dir
+	}
+	return fallback
+}
+
+func readCgroupV2Memory(dir string) (cgroupMemory, error) {
+	var memory cgroupMemory
+
+	usage, err := readUint(filepath.Join(dir, "memory.current"))
+	if err != nil {
+		return memory, err
+	}
+	memory.usage = subtractInactiveFile(usage, filepath.Join(dir, "memory.stat"), "inactive_file")
+
+	b, err := os.ReadFile(filepath.Join(dir, "memory.max"))
+	if err != nil {
+		return memory, err
+	}
+	if limit := strings.TrimSpace(string(b)); limit != "max" {
+		if memory.limit, err = strconv.ParseUint(limit, 10, 64); err != nil {
+			return memory, err
+		}
+	}
+	return memory, nil
+}
+
+func readCgroupV1Memory(dir string) (cgroupMemory, error) {
+	var memory cgroupMemory
+
+	usage, err := readUint(filepath.Join(dir, "memory.usage_in_bytes"))
+	if err != nil {
+		return memory, err
+	}
+	memory.usage = subtractInactiveFile(usage, filepath.Join(dir, "memory.stat"), "total_inactive_file")
+
+	limit, err := readUint(filepath.Join(dir, "memory.limit_in_bytes"))
+	if err != nil {
+		return memory, err
+	}
+	if limit < cgroupV1Unlimited {
+		memory.limit = limit
+	}
+	return memory, nil
+}
+
+// subtractInactiveFile returns the provided usage minus the inactive page cache read from the provided memory.stat file,
+// or the usage as is if it cannot be read
+func subtractInactiveFile(usage uint64, path, key string) uint64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return usage
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != key {
+			continue
+		}
+		inactive, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil || inactive > usage {
+			return usage
+		}
+		return usage - inactive
+	}
+	return usage
+}
+
+// readUint returns the unsigned integer held in the provided file
+func readUint(path string) (uint64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, fmt.Errorf("no cgroup memory controller found: %w", err)
+		}
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+}
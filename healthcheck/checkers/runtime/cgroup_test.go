@@ -0,0 +1,193 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func writeCgroupFiles(dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		So(os.MkdirAll(filepath.Dir(path), 0o700), ShouldBeNil)
+		So(os.WriteFile(path, []byte(content), 0o600), ShouldBeNil)
+	}
+}
+
+// writeProcCgroup writes a /proc/self/cgroup file with the provided content, and returns its path
+func writeProcCgroup(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "cgroup")
+	So(os.WriteFile(path, []byte(content), 0o600), ShouldBeNil)
+	return path
+}
+
+func TestCgroupMemoryChecker(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a cgroup v2 using 600 MiB of its 1 GiB limit, 100 MiB of which is inactive page cache", t, func() {
+		root := t.TempDir()
+		writeCgroupFiles(root, map[string]string{
+			"memory.current": "629145600\n",
+			"memory.max":     "1073741824\n",
+			"memory.stat":    "anon 400000000\ninactive_file 104857600\nactive_file 0\n",
+		})
+		procPath := writeProcCgroup(t, "0::/\n")
+		state := healthcheck.NewCheckState("memory")
+
+		Convey("When the checker is run with thresholds above the usage", func() {
+			So(cgroupMemoryChecker(root, procPath, 0.8, 0.9)(ctx, state), ShouldBeNil)
+
+			Convey("Then the inactive page cache is not counted and the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "cgroup memory usage is 500.0 MiB of 1.0 GiB (49%)")
+			})
+		})
+
+		Convey("When the checker is run with a warning threshold reached by the usage", func() {
+			So(cgroupMemoryChecker(root, procPath, 0.4, 0.9)(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldEqual, "cgroup memory usage is 500.0 MiB of 1.0 GiB (49%), reaching the warning threshold of 40%")
+			})
+		})
+	})
+
+	Convey("Given a cgroup v2 without a memory limit", t, func() {
+		root := t.TempDir()
+		writeCgroupFiles(root, map[string]string{
+			"memory.current": "1048576\n",
+			"memory.max":     "max\n",
+		})
+		procPath := writeProcCgroup(t, "0::/\n")
+		state := healthcheck.NewCheckState("memory")
+
+		Convey("When the checker is run", func() {
+			So(cgroupMemoryChecker(root, procPath, 0.8, 0.9)(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "cgroup memory usage is 1.0 MiB, without a limit")
+			})
+		})
+	})
+
+	Convey("Given a cgroup v1 using 950 MiB of its 1 GiB limit", t, func() {
+		root := t.TempDir()
+		writeCgroupFiles(root, map[string]string{
+			"memory/memory.usage_in_bytes": "996147200\n",
+			"memory/memory.limit_in_bytes": "1073741824\n",
+			"memory/memory.stat":           "cache 0\ntotal_inactive_file 0\n",
+		})
+		procPath := writeProcCgroup(t, "5:cpu,cpuacct:/\n4:memory:/\n")
+		state := healthcheck.NewCheckState("memory")
+
+		Convey("When the checker is run", func() {
+			So(cgroupMemoryChecker(root, procPath, 0.8, 0.9)(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is CRITICAL", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.Message(), ShouldEqual, "cgroup memory usage is 950.0 MiB of 1.0 GiB (93%), reaching the critical threshold of 90%")
+			})
+		})
+	})
+
+	Convey("Given a cgroup v1 without a memory limit", t, func() {
+		root := t.TempDir()
+		writeCgroupFiles(root, map[string]string{
+			"memory/memory.usage_in_bytes": "1048576\n",
+			"memory/memory.limit_in_bytes": "9223372036854771712\n",
+		})
+		procPath := writeProcCgroup(t, "5:cpu,cpuacct:/\n4:memory:/\n")
+		state := healthcheck.NewCheckState("memory")
+
+		Convey("When the checker is run", func() {
+			So(cgroupMemoryChecker(root, procPath, 0.8, 0.9)(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "cgroup memory usage is 1.0 MiB, without a limit")
+			})
+		})
+	})
+
+	Convey("Given a cgroup v2 nested in the hierarchy, with a parent that has no memory files", t, func() {
+		root := t.TempDir()
+		writeCgroupFiles(root, map[string]string{
+			"cgroup.controllers":                        "cpu memory\n",
+			"system.slice/app.service/memory.current":   "536870912\n",
+			"system.slice/app.service/memory.max":       "1073741824\n",
+			"system.slice/app.service/memory.stat":      "inactive_file 0\n",
+			"system.slice/other.service/memory.current": "1048576\n",
+			"system.slice/other.service/memory.max":     "max\n",
+		})
+		procPath := writeProcCgroup(t, "0::/system.slice/app.service\n")
+		state := healthcheck.NewCheckState("memory")
+
+		Convey("When the checker is run", func() {
+			So(cgroupMemoryChecker(root, procPath, 0.8, 0.9)(ctx, state), ShouldBeNil)
+
+			Convey("Then the memory of the cgroup of the app is checked", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "cgroup memory usage is 512.0 MiB of 1.0 GiB (50%)")
+			})
+		})
+	})
+
+	Convey("Given a cgroup v1 nested in the hierarchy of the memory controller", t, func() {
+		root := t.TempDir()
+		writeCgroupFiles(root, map[string]string{
+			"memory/memory.usage_in_bytes":                   "1048576\n",
+			"memory/memory.limit_in_bytes":                   "9223372036854771712\n",
+			"memory/kubepods/pod1/app/memory.usage_in_bytes": "996147200\n",
+			"memory/kubepods/pod1/app/memory.limit_in_bytes": "1073741824\n",
+		})
+		procPath := writeProcCgroup(t, "12:pids:/kubepods/pod1/app\n5:memory:/kubepods/pod1/app\n4:cpu,cpuacct:/kubepods/pod1/app\n0::/\n")
+		state := healthcheck.NewCheckState("memory")
+
+		Convey("When the checker is run", func() {
+			So(cgroupMemoryChecker(root, procPath, 0.8, 0.9)(ctx, state), ShouldBeNil)
+
+			Convey("Then the memory of the cgroup of the app is checked", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.Message(), ShouldEqual, "cgroup memory usage is 950.0 MiB of 1.0 GiB (93%), reaching the critical threshold of 90%")
+			})
+		})
+	})
+
+	Convey("Given a container that only has its own cgroup v1 mounted at the root of the hierarchy", t, func() {
+		root := t.TempDir()
+		writeCgroupFiles(root, map[string]string{
+			"memory/memory.usage_in_bytes": "536870912\n",
+			"memory/memory.limit_in_bytes": "1073741824\n",
+		})
+		procPath := writeProcCgroup(t, "4:memory:/docker/0123456789ab\n")
+		state := healthcheck.NewCheckState("memory")
+
+		Convey("When the checker is run", func() {
+			So(cgroupMemoryChecker(root, procPath, 0.8, 0.9)(ctx, state), ShouldBeNil)
+
+			Convey("Then the memory of the cgroup at the root is checked", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "cgroup memory usage is 512.0 MiB of 1.0 GiB (50%)")
+			})
+		})
+	})
+
+	Convey("Given no cgroup memory controller", t, func() {
+		state := healthcheck.NewCheckState("memory")
+
+		Convey("When the checker is run", func() {
+			So(cgroupMemoryChecker(t.TempDir(), filepath.Join(t.TempDir(), "cgroup"), 0.8, 0.9)(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldStartWith, "failed to read the cgroup memory: no cgroup memory controller found")
+			})
+		})
+	})
+}
@@ -0,0 +1,58 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+// fdDir is the directory listing the open file descriptors of the app
+const fdDir = "/proc/self/fd"
+
+// FileDescriptorChecker returns a checker of the ratio, between 0 and 1, of the open file descriptors to the soft
+// RLIMIT_NOFILE limit against the provided thresholds, e.g. 0.8 for a warning once 80% of the file descriptors are open.
+// The state is OK if the app has no file descriptor limit.
+// File descriptors are only checked on Linux, the state is WARNING on other platforms.
+func FileDescriptorChecker(warning, critical float64) healthcheck.Checker {
+	return fileDescriptorChecker(fdDir, getFileDescriptorLimit, warning, critical)
+}
+
+func fileDescriptorChecker(dir string, getLimit func() (uint64, error), warning, critical float64) healthcheck.Checker {
+	return func(ctx context.Context, state *healthcheck.CheckState) error {
+		limit, err := getLimit()
+		if err != nil {
+			return state.Update(healthcheck.StatusWarning, fmt.Sprintf("failed to get the file descriptor limit: %s", err), 0)
+		}
+
+		open, err := countFileDescriptors(dir)
+		if err != nil {
+			return state.Update(healthcheck.StatusWarning, fmt.Sprintf("failed to count the open file descriptors: %s", err), 0)
+		}
+
+		if limit == 0 {
+			return state.Update(healthcheck.StatusOK, fmt.Sprintf("%d file descriptors are open, without a limit", open), 0)
+		}
+
+		ratio := float64(open) / float64(limit)
+		status := getStatus(ratio, warning, critical)
+		message := withThreshold(fmt.Sprintf("%d of %d file descriptors are open (%s)", open, limit, formatPercent(ratio)), status, formatPercent(warning), formatPercent(critical))
+		return state.Update(status, message, 0)
+	}
+}
+
+// countFileDescriptors returns the number of open file descriptors listed in the provided directory,
+// including the one opened to list them
+func countFileDescriptors(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// formatPercent returns the provided ratio as a percentage, e.g. 0.8 as 80%
+func formatPercent(ratio float64) string {
+	return fmt.Sprintf("%.0f%%", ratio*100)
+}
@@ -0,0 +1,20 @@
+//go:build linux
+
+package runtime
+
+import "syscall"
+
+// rlimInfinity is the value of a resource limit that is not limited, as syscall.RLIM_INFINITY is negative on Linux
+const rlimInfinity = ^uint64(0)
+
+// getFileDescriptorLimit returns the soft limit of open file descriptors of the app, or 0 if it has no limit
+func getFileDescriptorLimit() (uint64, error) {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return 0, err
+	}
+	if limit.Cur == rlimInfinity {
+		return 0, nil
+	}
+	return limit.Cur, nil
+}
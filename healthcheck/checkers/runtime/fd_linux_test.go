@@ -0,0 +1,26 @@
+//go:build linux
+
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFileDescriptorCheckerLinux(t *testing.T) {
+	Convey("Given a file descriptor checker of the app", t, func() {
+		state := healthcheck.NewCheckState("file descriptors")
+
+		Convey("When it is run with thresholds above the open file descriptors", func() {
+			So(FileDescriptorChecker(1, 1)(context.Background(), state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldContainSubstring, "file descriptors are open")
+			})
+		})
+	})
+}
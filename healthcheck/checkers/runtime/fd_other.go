@@ -0,0 +1,13 @@
+//go:build !linux
+
+package runtime
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// getFileDescriptorLimit returns an error, as file descriptors are only checked on Linux
+func getFileDescriptorLimit() (uint64, error) {
+	return 0, fmt.Errorf("file descriptors are not checked on %s", runtime.GOOS)
+}
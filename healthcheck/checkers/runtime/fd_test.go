@@ -0,0 +1,89 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFileDescriptorChecker(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a directory listing 8 open file descriptors", t, func() {
+		dir := t.TempDir()
+		for i := 0; i < 8; i++ {
+			So(os.WriteFile(filepath.Join(dir, fmt.Sprint(i)), nil, 0o600), ShouldBeNil)
+		}
+		state := healthcheck.NewCheckState("file descriptors")
+
+		Convey("When the checker is run with a limit well above them", func() {
+			checker := fileDescriptorChecker(dir, func() (uint64, error) { return 1024, nil }, 0.5, 0.9)
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "8 of 1024 file descriptors are open (1%)")
+			})
+		})
+
+		Convey("When the checker is run with a limit reaching the warning threshold", func() {
+			checker := fileDescriptorChecker(dir, func() (uint64, error) { return 10, nil }, 0.5, 0.9)
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldEqual, "8 of 10 file descriptors are open (80%), reaching the warning threshold of 50%")
+			})
+		})
+
+		Convey("When the checker is run with a limit reaching the critical threshold", func() {
+			checker := fileDescriptorChecker(dir, func() (uint64, error) { return 8, nil }, 0.5, 0.9)
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is CRITICAL", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.Message(), ShouldEqual, "8 of 8 file descriptors are open (100%), reaching the critical threshold of 90%")
+			})
+		})
+
+		Convey("When the checker is run without a limit", func() {
+			checker := fileDescriptorChecker(dir, func() (uint64, error) { return 0, nil }, 0.5, 0.9)
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "8 file descriptors are open, without a limit")
+			})
+		})
+
+		Convey("When the limit cannot be read", func() {
+			checker := fileDescriptorChecker(dir, func() (uint64, error) { return 0, errors.New("unsupported") }, 0.5, 0.9)
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldEqual, "failed to get the file descriptor limit: unsupported")
+			})
+		})
+	})
+
+	Convey("Given a directory that does not exist", t, func() {
+		state := healthcheck.NewCheckState("file descriptors")
+
+		Convey("When the checker is run", func() {
+			checker := fileDescriptorChecker(filepath.Join(t.TempDir(), "fd"), func() (uint64, error) { return 1024, nil }, 0.5, 0.9)
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldStartWith, "failed to count the open file descriptors: ")
+			})
+		})
+	})
+}
@@ -0,0 +1,111 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+// gcPauseMetric is the runtime metric of the distribution of the stop-the-world pauses of the garbage collector
+const gcPauseMetric = "/sched/pauses/total/gc:seconds"
+
+// gcPauseChecker checks a percentile of the garbage collector pauses since its previous run
+type gcPauseChecker struct {
+	read       func() (*metrics.Float64Histogram, error)
+	percentile float64
+	warning    time.Duration
+	critical   time.Duration
+	mutex      sync.Mutex
+	previous   []uint64
+}
+
+// GCPauseChecker returns a checker of the provided percentile, between 0 and 100, of the pauses of the garbage
+// collector against the provided thresholds, e.g. 99 for the 99th percentile. Only the pauses since the previous run
+// of the checker are taken into account, so that the check recovers once the pauses are short again.
+func GCPauseChecker(percentile float64, warning, critical time.Duration) healthcheck.Checker {
+	return newGCPauseChecker(readGCPauses, percentile, warning, critical).check
+}
+
+func newGCPauseChecker(read func() (*metrics.Float64Histogram, error), percentile float64, warning, critical time.Duration) *gcPauseChecker {
+	return &gcPauseChecker{
+		read:       read,
+		percentile: percentile,
+		warning:    warning,
+		critical:   critical,
+	}
+}
+
+func (c *gcPauseChecker) check(ctx context.Context, state *healthcheck.CheckState) error {
+	histogram, err := c.read()
+	if err != nil {
+		return state.Update(healthcheck.StatusWarning, fmt.Sprintf("failed to read the GC pauses: %s", err), 0)
+	}
+
+	c.mutex.Lock()
+	counts := subtractCounts(histogram.Counts, c.previous)
+	c.previous = histogram.Counts
+	c.mutex.Unlock()
+
+	pause, ok := getPercentile(counts, histogram.Buckets, c.percentile)
+	if !ok {
+		return state.Update(healthcheck.StatusOK, "no GC pauses since the previous check", 0)
+	}
+
+	status := getStatus(float64(pause), float64(c.warning), float64(c.critical))
+	message := withThreshold(fmt.Sprintf("%gth percentile of the GC pauses is %s", c.percentile, pause), status, c.warning.String(), c.critical.String())
+	return state.Update(status, message, 0)
+}
+
+// readGCPauses returns the distribution of the pauses of the garbage collector since the app started
+func readGCPauses() (*metrics.Float64Histogram, error) {
+	sample := []metrics.Sample{{Name: gcPauseMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return nil, fmt.Errorf("metric %s is not supported", gcPauseMetric)
+	}
+	return sample[0].Value.Float64Histogram(), nil
+}
+
+// subtractCounts returns the counts of the histogram buckets minus the previous counts, if any
+func subtractCounts(counts, previous []uint64) []uint64 {
+	if len(previous) != len(counts) {
+		return counts
+	}
+	diff := make([]uint64, len(counts))
+	for i := range counts {
+		diff[i] = counts[i] - previous[i]
+	}
+	return diff
+}
+
+// getPercentile returns the upper bound of the histogram bucket holding the provided percentile, or its lower bound
+// if the bucket is unbounded. False is returned if the histogram is empty.
+func getPercentile(counts []uint64, buckets []float64, percentile float64) (time.Duration, bool) {
+	var total uint64
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 {
+		return 0, false
+	}
+
+	target := min(uint64(math.Ceil(percentile/100*float64(total))), total)
+	var cumulative uint64
+	for i, count := range counts {
+		cumulative += count
+		if cumulative < target || count == 0 {
+			continue
+		}
+		bound := buckets[i+1]
+		if math.IsInf(bound, 1) {
+			bound = buckets[i]
+		}
+		return time.Duration(bound * float64(time.Second)), true
+	}
+	return 0, false
+}
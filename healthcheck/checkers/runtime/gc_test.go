@@ -0,0 +1,130 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGCPauseChecker(t *testing.T) {
+	ctx := context.Background()
+	buckets := []float64{0, 0.001, 0.01, 0.1, math.Inf(1)} // 0-1ms, 1-10ms, 10-100ms, 100ms+
+
+	Convey("Given a GC pause checker of the 99th percentile with a warning threshold of 10ms and a critical threshold of 100ms", t, func() {
+		histogram := &metrics.Float64Histogram{Buckets: buckets}
+		var readErr error
+		checker := newGCPauseChecker(func() (*metrics.Float64Histogram, error) {
+			return &metrics.Float64Histogram{Counts: append([]uint64{}, histogram.Counts...), Buckets: histogram.Buckets}, readErr
+		}, 99, 10*time.Millisecond, 100*time.Millisecond).check
+		state := healthcheck.NewCheckState("gc")
+
+		Convey("When there has not been any GC pause", func() {
+			histogram.Counts = []uint64{0, 0, 0, 0}
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "no GC pauses since the previous check")
+			})
+		})
+
+		Convey("When the GC pauses are short", func() {
+			histogram.Counts = []uint64{100, 0, 0, 0}
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "99th percentile of the GC pauses is 1ms")
+			})
+		})
+
+		Convey("When the 99th percentile of the GC pauses reaches the warning threshold", func() {
+			histogram.Counts = []uint64{90, 9, 1, 0}
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldEqual, "99th percentile of the GC pauses is 10ms, reaching the warning threshold of 10ms")
+			})
+
+			Convey("When the GC pauses are short again on the next run", func() {
+				histogram.Counts = []uint64{190, 9, 1, 0}
+				So(checker(ctx, state), ShouldBeNil)
+
+				Convey("Then only the new pauses are taken into account and the state is OK", func() {
+					So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				})
+			})
+		})
+
+		Convey("When the 99th percentile of the GC pauses is in the unbounded bucket", func() {
+			histogram.Counts = []uint64{10, 0, 0, 10}
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is CRITICAL", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.Message(), ShouldEqual, "99th percentile of the GC pauses is 100ms, reaching the critical threshold of 100ms")
+			})
+		})
+
+		Convey("When the GC pauses cannot be read", func() {
+			readErr = errors.New("unsupported")
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldEqual, "failed to read the GC pauses: unsupported")
+			})
+		})
+	})
+
+	Convey("Given a GC pause checker reading the GC pauses of the app", t, func() {
+		state := healthcheck.NewCheckState("gc")
+		runtime.GC()
+
+		Convey("When it is run with thresholds well above the GC pauses", func() {
+			So(GCPauseChecker(99, time.Minute, time.Hour)(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldStartWith, "99th percentile of the GC pauses is ")
+			})
+		})
+	})
+}
+
+func TestGetPercentile(t *testing.T) {
+	buckets := []float64{0, 0.001, 0.01, math.Inf(1)}
+
+	Convey("Given a histogram of GC pauses", t, func() {
+		counts := []uint64{50, 40, 10}
+
+		Convey("Then the percentiles are the upper bounds of their buckets", func() {
+			p, ok := getPercentile(counts, buckets, 50)
+			So(ok, ShouldBeTrue)
+			So(p, ShouldEqual, time.Millisecond)
+
+			p, _ = getPercentile(counts, buckets, 90)
+			So(p, ShouldEqual, 10*time.Millisecond)
+		})
+
+		Convey("Then the percentiles in the unbounded bucket are its lower bound", func() {
+			p, _ := getPercentile(counts, buckets, 99)
+			So(p, ShouldEqual, 10*time.Millisecond)
+		})
+	})
+
+	Convey("Given an empty histogram", t, func() {
+		Convey("Then there is no percentile", func() {
+			_, ok := getPercentile([]uint64{0, 0, 0}, buckets, 99)
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,99 @@
+// Package runtime provides health checkers for the resources used by the app itself, such as its memory,
+// goroutines, garbage collector pauses and file descriptors.
+//
+// Every checker has a warning and a critical threshold: the check state is WARNING once the measured value reaches
+// the warning threshold, and CRITICAL once it reaches the critical threshold. The state is WARNING if the value
+// cannot be measured.
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+// heapMetric is the runtime metric of the memory occupied by live objects and dead objects not yet freed by the garbage collector
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// HeapChecker returns a checker of the size of the heap, in bytes, against the provided thresholds
+func HeapChecker(warning, critical uint64) healthcheck.Checker {
+	return heapChecker(readHeapSize, warning, critical)
+}
+
+func heapChecker(read func() (uint64, error), warning, critical uint64) healthcheck.Checker {
+	return func(ctx context.Context, state *healthcheck.CheckState) error {
+		size, err := read()
+		if err != nil {
+			return state.Update(healthcheck.StatusWarning, fmt.Sprintf("failed to read the heap size: %s", err), 0)
+		}
+
+		status := getStatus(float64(size), float64(warning), float64(critical))
+		message := withThreshold(fmt.Sprintf("heap size is %s", formatBytes(size)), status, formatBytes(warning), formatBytes(critical))
+		return state.Update(status, message, 0)
+	}
+}
+
+// readHeapSize returns the size of the heap, in bytes, without stopping the world as runtime.ReadMemStats does
+func readHeapSize() (uint64, error) {
+	sample := []metrics.Sample{{Name: heapMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0, fmt.Errorf("metric %s is not supported", heapMetric)
+	}
+	return sample[0].Value.Uint64(), nil
+}
+
+// GoroutineChecker returns a checker of the number of goroutines against the provided thresholds
+func GoroutineChecker(warning, critical int) healthcheck.Checker {
+	return goroutineChecker(runtime.NumGoroutine, warning, critical)
+}
+
+func goroutineChecker(count func() int, warning, critical int) healthcheck.Checker {
+	return func(ctx context.Context, state *healthcheck.CheckState) error {
+		n := count()
+		status := getStatus(float64(n), float64(warning), float64(critical))
+		message := withThreshold(fmt.Sprintf("%d goroutines are running", n), status, fmt.Sprint(warning), fmt.Sprint(critical))
+		return state.Update(status, message, 0)
+	}
+}
+
+// getStatus returns the status of the provided value against the provided thresholds
+func getStatus(value, warning, critical float64) string {
+	switch {
+	case value >= critical:
+		return healthcheck.StatusCritical
+	case value >= warning:
+		return healthcheck.StatusWarning
+	default:
+		return healthcheck.StatusOK
+	}
+}
+
+// withThreshold appends the threshold that has been reached for the provided status, if any, to the provided message
+func withThreshold(message, status, warning, critical string) string {
+	switch status {
+	case healthcheck.StatusCritical:
+		return fmt.Sprintf("%s, reaching the critical threshold of %s", message, critical)
+	case healthcheck.StatusWarning:
+		return fmt.Sprintf("%s, reaching the warning threshold of %s", message, warning)
+	default:
+		return message
+	}
+}
+
+// formatBytes returns a human readable size for the provided number of bytes, e.g. 1.5 MiB
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit && exp < 5; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,137 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHeapChecker(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a heap checker with a warning threshold of 100 MiB and a critical threshold of 200 MiB", t, func() {
+		var size uint64
+		var readErr error
+		checker := heapChecker(func() (uint64, error) { return size, readErr }, 100<<20, 200<<20)
+		state := healthcheck.NewCheckState("heap")
+
+		Convey("When the heap is below the warning threshold", func() {
+			size = 50 << 20
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "heap size is 50.0 MiB")
+			})
+		})
+
+		Convey("When the heap reaches the warning threshold", func() {
+			size = 150 << 20
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldEqual, "heap size is 150.0 MiB, reaching the warning threshold of 100.0 MiB")
+			})
+		})
+
+		Convey("When the heap reaches the critical threshold", func() {
+			size = 2 << 30
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is CRITICAL", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.Message(), ShouldEqual, "heap size is 2.0 GiB, reaching the critical threshold of 200.0 MiB")
+			})
+		})
+
+		Convey("When the heap size cannot be read", func() {
+			readErr = errors.New("unsupported")
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldEqual, "failed to read the heap size: unsupported")
+			})
+		})
+	})
+
+	Convey("Given a heap checker reading the heap of the app", t, func() {
+		state := healthcheck.NewCheckState("heap")
+
+		Convey("When it is run with thresholds well above the heap size", func() {
+			So(HeapChecker(1<<40, 2<<40)(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldStartWith, "heap size is ")
+			})
+		})
+	})
+}
+
+func TestGoroutineChecker(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a goroutine checker with a warning threshold of 100 and a critical threshold of 1000", t, func() {
+		var n int
+		checker := goroutineChecker(func() int { return n }, 100, 1000)
+		state := healthcheck.NewCheckState("goroutines")
+
+		Convey("When few goroutines are running", func() {
+			n = 10
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is OK", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, "10 goroutines are running")
+			})
+		})
+
+		Convey("When the goroutines reach the warning threshold", func() {
+			n = 100
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is WARNING", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.Message(), ShouldEqual, "100 goroutines are running, reaching the warning threshold of 100")
+			})
+		})
+
+		Convey("When the goroutines have leaked past the critical threshold", func() {
+			n = 5000
+			So(checker(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is CRITICAL", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+				So(state.Message(), ShouldEqual, "5000 goroutines are running, reaching the critical threshold of 1000")
+			})
+		})
+	})
+
+	Convey("Given a goroutine checker counting the goroutines of the app", t, func() {
+		state := healthcheck.NewCheckState("goroutines")
+
+		Convey("When it is run with a critical threshold of 1", func() {
+			So(GoroutineChecker(1, 1)(ctx, state), ShouldBeNil)
+
+			Convey("Then the state is CRITICAL", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+			})
+		})
+	})
+}
+
+func TestFormatBytes(t *testing.T) {
+	Convey("Given sizes in bytes", t, func() {
+		Convey("Then they are formatted in the largest unit below them", func() {
+			So(formatBytes(0), ShouldEqual, "0 B")
+			So(formatBytes(1023), ShouldEqual, "1023 B")
+			So(formatBytes(1536), ShouldEqual, "1.5 KiB")
+			So(formatBytes(5<<30), ShouldEqual, "5.0 GiB")
+		})
+	})
+}
@@ -0,0 +1,86 @@
+package healthcheck
+
+import "time"
+
+// Clock provides the current time, tickers and timers to the health check, so that time can be controlled in tests.
+// The healthcheck/clocktest package provides a fake clock that can be advanced manually.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Ticker represents a ticker created by a Clock, as a time.Ticker does
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// Timer represents a timer created by a Clock, as a time.Timer does.
+// The channel of a timer created by AfterFunc is nil.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// realClock is the Clock used by default, backed by the time package
+type realClock struct{}
+
+// realTicker is a Ticker backed by a time.Ticker
+type realTicker struct {
+	*time.Ticker
+}
+
+// realTimer is a Timer backed by a time.Timer
+type realTimer struct {
+	*time.Timer
+}
+
+// WithClock sets the clock used to get the current time and to create tickers and timers. Defaults to the system clock.
+// The check timeouts are enforced with context deadlines, which always use the system clock.
+func WithClock(clock Clock) Option {
+	return func(hc *HealthCheck) {
+		hc.clock = clock
+	}
+}
+
+// getClock returns the clock of the health check, or the system clock if none has been set
+func (hc *HealthCheck) getClock() Clock {
+	if hc.clock == nil {
+		return realClock{}
+	}
+	return hc.clock
+}
+
+// Now returns the current time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker returns a new Ticker that ticks with the provided period
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+// NewTimer returns a new Timer that fires once the provided duration has passed
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+// AfterFunc calls the provided function in its own goroutine once the provided duration has passed
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+// C returns the channel on which the ticks are delivered
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// C returns the channel on which the time is delivered once the timer fires
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
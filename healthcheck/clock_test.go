@@ -0,0 +1,135 @@
+package healthcheck_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck/clocktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// receiveState returns the next check state sent on the provided channel, failing if none is sent within a second
+func receiveState(states <-chan *healthcheck.CheckState) *healthcheck.CheckState {
+	select {
+	case state := <-states:
+		return state
+	case <-time.After(time.Second):
+		So("the checker was not run", ShouldBeEmpty)
+		return nil
+	}
+}
+
+// statusSubscriber is a subscriber that sends every health update on a channel
+type statusSubscriber chan string
+
+func (s statusSubscriber) OnHealthUpdate(status string) {
+	s <- status
+}
+
+func TestWithClock(t *testing.T) {
+	version := healthcheck.VersionInfo{BuildTime: time.Unix(0, 0), Version: "1.0.0"}
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	Convey("Given a started health check with a fake clock and a check that reports CRITICAL", t, func() {
+		clock := clocktest.NewClock(t0)
+		hc := healthcheck.New(version, time.Minute, time.Hour, healthcheck.WithClock(clock))
+
+		states := make(chan *healthcheck.CheckState, 10)
+		So(hc.AddCheck("check", func(ctx context.Context, state *healthcheck.CheckState) error {
+			err := state.Update(healthcheck.StatusCritical, "unreachable", 0)
+			states <- state
+			return err
+		}), ShouldBeNil)
+
+		So(hc.Start(context.Background()), ShouldBeNil)
+		defer hc.Stop()
+		state := receiveState(states)
+
+		Convey("Then the check state is timed by the fake clock", func() {
+			So(*state.LastChecked(), ShouldEqual, t0)
+		})
+
+		Convey("Then the app is WARNING until the critical timeout has passed, then CRITICAL", func() {
+			So(hc.GetChecksStatus(), ShouldEqual, healthcheck.StatusWarning)
+
+			clock.Advance(time.Minute)
+			So(hc.GetChecksStatus(), ShouldEqual, healthcheck.StatusWarning)
+
+			clock.Advance(time.Millisecond)
+			So(hc.GetChecksStatus(), ShouldEqual, healthcheck.StatusCritical)
+		})
+
+		Convey("Then subscribers are notified once the critical timeout has passed, without any new state of the check", func() {
+			subscriber := make(statusSubscriber, 10)
+			hc.SubscribeAll(subscriber)
+
+			clock.Advance(time.Minute)
+			select {
+			case status := <-subscriber:
+				So(status, ShouldNotEqual, healthcheck.StatusCritical)
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			clock.Advance(time.Millisecond)
+			select {
+			case status := <-subscriber:
+				So(status, ShouldEqual, healthcheck.StatusCritical)
+			case <-time.After(time.Second):
+				So("the subscriber was not notified", ShouldBeEmpty)
+			}
+		})
+
+		Convey("Then subscribers are not notified once the critical timeout has passed after the health check is stopped", func() {
+			subscriber := make(statusSubscriber, 10)
+			hc.SubscribeAll(subscriber)
+			So(hc.Stop(), ShouldBeNil)
+			for len(subscriber) > 0 {
+				<-subscriber
+			}
+
+			clock.Advance(2 * time.Minute)
+			select {
+			case status := <-subscriber:
+				So(status, ShouldBeEmpty)
+			case <-time.After(100 * time.Millisecond):
+			}
+			So(hc.SubscriberStats(), ShouldBeEmpty)
+		})
+
+		Convey("Then the check is run again once the fake clock is advanced by its interval", func() {
+			clock.Advance(2 * time.Hour)
+			state = receiveState(states)
+			So(state.LastChecked().After(t0), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given a health check that has not been started, with a fake clock and 2 checks", t, func() {
+		clock := clocktest.NewClock(t0)
+		hc := healthcheck.New(version, time.Minute, time.Hour, healthcheck.WithClock(clock))
+		checker := func(ctx context.Context, state *healthcheck.CheckState) error {
+			return state.Update(healthcheck.StatusOK, "ok", 0)
+		}
+		So(hc.AddCheck("check 1", checker), ShouldBeNil)
+		So(hc.AddCheck("check 2", checker), ShouldBeNil)
+		So(clock.Waiters(), ShouldEqual, 2)
+
+		Convey("When a check is replaced", func() {
+			_, err := hc.ReplaceCheck("check 1", checker)
+			So(err, ShouldBeNil)
+
+			Convey("Then the ticker of the old check is stopped", func() {
+				So(clock.Waiters(), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When a check is removed", func() {
+			So(hc.RemoveCheckByName("check 1"), ShouldBeNil)
+
+			Convey("Then its ticker is stopped", func() {
+				So(clock.Waiters(), ShouldEqual, 1)
+			})
+		})
+	})
+}
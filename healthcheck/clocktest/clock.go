@@ -0,0 +1,258 @@
+// Package clocktest provides a fake healthcheck.Clock, whose time only moves when it is advanced, so that the
+// time-based behaviour of a health check, e.g. the WARNING to CRITICAL transition, can be tested deterministically.
+package clocktest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+// Clock is a fake healthcheck.Clock. Its tickers and timers fire when the clock is advanced past their expiry time.
+type Clock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	changed chan struct{}
+}
+
+// waiter is a ticker or timer created by the fake clock
+type waiter struct {
+	clock  *Clock
+	when   time.Time
+	period time.Duration
+	c      chan time.Time
+	f      func()
+	active bool
+}
+
+// ticker is a healthcheck.Ticker created by the fake clock
+type ticker struct {
+	*waiter
+}
+
+// timer is a healthcheck.Timer created by the fake clock
+type timer struct {
+	*waiter
+}
+
+// NewClock returns a pointer to a new fake clock set to the provided time
+func NewClock(now time.Time) *Clock {
+	return &Clock{
+		now:     now,
+		changed: make(chan struct{}),
+	}
+}
+
+// Now returns the current time of the clock
+func (c *Clock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+// NewTicker returns a new ticker that ticks every time the clock is advanced past the provided period
+func (c *Clock) NewTicker(d time.Duration) healthcheck.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return &ticker{c.addWaiter(d, d, nil)}
+}
+
+// NewTimer returns a new timer that fires once the clock is advanced past the provided duration
+func (c *Clock) NewTimer(d time.Duration) healthcheck.Timer {
+	return &timer{c.addWaiter(d, 0, nil)}
+}
+
+// AfterFunc calls the provided function in its own goroutine once the clock is advanced past the provided duration
+func (c *Clock) AfterFunc(d time.Duration, f func()) healthcheck.Timer {
+	return &timer{c.addWaiter(d, 0, f)}
+}
+
+// Advance moves the clock forward by the provided duration, firing every ticker and timer that expires on the way,
+// in order. A ticker that expires several times ticks for every period, but at most one tick is kept in its channel.
+func (c *Clock) Advance(d time.Duration) {
+	if d < 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	target := c.now.Add(d)
+	for {
+		w, when := c.next(target)
+		if w == nil {
+			break
+		}
+		c.now = when
+		w.fire()
+	}
+	c.now = target
+}
+
+// Set moves the clock forward to the provided time, firing every ticker and timer that expires on the way.
+// The clock cannot be moved backwards, so an earlier time is ignored.
+func (c *Clock) Set(t time.Time) {
+	c.Advance(t.Sub(c.Now()))
+}
+
+// Waiters returns the number of tickers and timers that have not expired or been stopped yet
+func (c *Clock) Waiters() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.waiters)
+}
+
+// BlockUntil waits until there are at least the provided number of tickers and timers that have not expired or
+// been stopped, e.g. to make sure that a goroutine is waiting on a timer before the clock is advanced
+func (c *Clock) BlockUntil(n int) {
+	c.mutex.Lock()
+	for len(c.waiters) < n {
+		changed := c.changed
+		c.mutex.Unlock()
+		<-changed
+		c.mutex.Lock()
+	}
+	c.mutex.Unlock()
+}
+
+// addWaiter adds a ticker or timer that expires after the provided duration
+func (c *Clock) addWaiter(d, period time.Duration, f func()) *waiter {
+	w := &waiter{
+		clock:  c,
+		period: period,
+		f:      f,
+	}
+	if f == nil {
+		w.c = make(chan time.Time, 1)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.schedule(w, d)
+	return w
+}
+
+// schedule activates the waiter to expire after the provided duration. The caller must hold the mutex.
+func (c *Clock) schedule(w *waiter, d time.Duration) {
+	w.when = c.now.Add(d)
+	if !w.active {
+		w.active = true
+		c.waiters = append(c.waiters, w)
+		c.notifyChanged()
+	}
+	if d <= 0 && w.period == 0 {
+		c.remove(w)
+		w.fire()
+	}
+}
+
+// next returns the waiter that expires first and its expiry time, as long as it expires no later than the provided time.
+// The caller must hold the mutex.
+func (c *Clock) next(target time.Time) (*waiter, time.Time) {
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].when.Before(c.waiters[j].when)
+	})
+	if len(c.waiters) == 0 || c.waiters[0].when.After(target) {
+		return nil, time.Time{}
+	}
+	w := c.waiters[0]
+	when := w.when
+	if w.period == 0 {
+		c.remove(w)
+	} else {
+		w.when = w.when.Add(w.period)
+	}
+	return w, when
+}
+
+// remove deactivates the waiter. The caller must hold the mutex.
+func (c *Clock) remove(w *waiter) bool {
+	if !w.active {
+		return false
+	}
+	w.active = false
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			break
+		}
+	}
+	c.notifyChanged()
+	return true
+}
+
+// notifyChanged wakes up any BlockUntil call. The caller must hold the mutex.
+func (c *Clock) notifyChanged() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// fire sends the current time on the channel of the waiter without blocking, or calls its function in a goroutine.
+// The caller must hold the mutex of the clock.
+func (w *waiter) fire() {
+	if w.f != nil {
+		go w.f()
+		return
+	}
+	select {
+	case w.c <- w.clock.now:
+	default:
+	}
+}
+
+// C returns the channel on which the ticks, or the expiry time, are delivered
+func (w *waiter) C() <-chan time.Time {
+	return w.c
+}
+
+// stop deactivates the ticker or timer, and returns true if it had not expired or been stopped yet
+func (w *waiter) stop() bool {
+	w.clock.mutex.Lock()
+	defer w.clock.mutex.Unlock()
+
+	return w.clock.remove(w)
+}
+
+// reset reschedules the ticker or timer to expire after the provided duration,
+// and returns true if it had not expired or been stopped yet
+func (w *waiter) reset(d time.Duration) bool {
+	w.clock.mutex.Lock()
+	defer w.clock.mutex.Unlock()
+
+	wasActive := w.active
+	if w.period > 0 {
+		w.period = d
+	}
+	w.clock.schedule(w, d)
+	return wasActive
+}
+
+// Stop turns off the ticker
+func (t *ticker) Stop() {
+	t.stop()
+}
+
+// Reset stops the ticker and resets its period to the provided duration
+func (t *ticker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.reset(d)
+}
+
+// Stop prevents the timer from firing, and returns false if it has already expired or been stopped
+func (t *timer) Stop() bool {
+	return t.stop()
+}
+
+// Reset changes the timer to expire after the provided duration, and returns true if it had not expired or been stopped
+func (t *timer) Reset(d time.Duration) bool {
+	return t.reset(d)
+}
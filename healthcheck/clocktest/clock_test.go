@@ -0,0 +1,128 @@
+package clocktest
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var t0 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// received returns the time sent on the provided channel, and false if none has been sent
+func received(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestClock(t *testing.T) {
+	Convey("Given a fake clock", t, func() {
+		clock := NewClock(t0)
+
+		Convey("Then its time only moves when it is advanced", func() {
+			So(clock.Now(), ShouldEqual, t0)
+			clock.Advance(time.Minute)
+			So(clock.Now(), ShouldEqual, t0.Add(time.Minute))
+			clock.Set(t0)
+			So(clock.Now(), ShouldEqual, t0.Add(time.Minute))
+		})
+
+		Convey("When a timer is created", func() {
+			timer := clock.NewTimer(time.Second)
+			So(clock.Waiters(), ShouldEqual, 1)
+
+			Convey("Then it fires once the clock is advanced past its expiry time, with the expiry time", func() {
+				clock.Advance(999 * time.Millisecond)
+				_, ok := received(timer.C())
+				So(ok, ShouldBeFalse)
+
+				clock.Advance(time.Second)
+				fired, ok := received(timer.C())
+				So(ok, ShouldBeTrue)
+				So(fired, ShouldEqual, t0.Add(time.Second))
+				So(clock.Waiters(), ShouldEqual, 0)
+				So(timer.Stop(), ShouldBeFalse)
+			})
+
+			Convey("Then it does not fire once it has been stopped", func() {
+				So(timer.Stop(), ShouldBeTrue)
+				clock.Advance(time.Minute)
+				_, ok := received(timer.C())
+				So(ok, ShouldBeFalse)
+			})
+
+			Convey("Then it fires after the new duration once it has been reset", func() {
+				So(timer.Reset(time.Minute), ShouldBeTrue)
+				clock.Advance(time.Second)
+				_, ok := received(timer.C())
+				So(ok, ShouldBeFalse)
+				clock.Advance(time.Minute)
+				_, ok = received(timer.C())
+				So(ok, ShouldBeTrue)
+			})
+		})
+
+		Convey("When a ticker is created", func() {
+			ticker := clock.NewTicker(time.Second)
+
+			Convey("Then it ticks every period, keeping at most one tick", func() {
+				clock.Advance(3 * time.Second)
+				tick, ok := received(ticker.C())
+				So(ok, ShouldBeTrue)
+				So(tick, ShouldEqual, t0.Add(time.Second))
+				_, ok = received(ticker.C())
+				So(ok, ShouldBeFalse)
+
+				clock.Advance(time.Second)
+				tick, ok = received(ticker.C())
+				So(ok, ShouldBeTrue)
+				So(tick, ShouldEqual, t0.Add(4*time.Second))
+			})
+
+			Convey("Then it stops ticking once it has been stopped", func() {
+				ticker.Stop()
+				clock.Advance(time.Minute)
+				_, ok := received(ticker.C())
+				So(ok, ShouldBeFalse)
+				So(clock.Waiters(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When a function is scheduled", func() {
+			called := make(chan time.Time, 1)
+			clock.AfterFunc(time.Second, func() {
+				called <- clock.Now()
+			})
+
+			Convey("Then it is called once the clock is advanced past its expiry time", func() {
+				clock.Advance(time.Second)
+				select {
+				case <-called:
+				case <-time.After(time.Second):
+					So("the function was not called", ShouldBeEmpty)
+				}
+			})
+		})
+
+		Convey("When a goroutine is waiting for a timer to be created", func() {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				clock.BlockUntil(1)
+			}()
+			clock.NewTimer(time.Second)
+
+			Convey("Then it is unblocked once it has", func() {
+				select {
+				case <-done:
+				case <-time.After(time.Second):
+					So("BlockUntil did not return", ShouldBeEmpty)
+				}
+			})
+		})
+	})
+}
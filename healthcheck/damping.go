@@ -0,0 +1,101 @@
+package healthcheck
+
+import (
+	"fmt"
+	"time"
+)
+
+// damping holds the configuration and counters used to dampen the status changes of a check
+type damping struct {
+	failureThreshold     int
+	successThreshold     int
+	flapWindow           time.Duration
+	flapMaxTransitions   int
+	consecutiveFailures  int
+	consecutiveSuccesses int
+	lastResult           string
+	transitions          []time.Time
+}
+
+// WithFailureThreshold sets the number of consecutive WARNING or CRITICAL results needed before an OK check
+// changes status. Until then the check keeps its OK status and message. Defaults to 1.
+func WithFailureThreshold(n int) CheckOption {
+	return func(c *Check) {
+		c.state.getDamping().failureThreshold = n
+	}
+}
+
+// WithSuccessThreshold sets the number of consecutive OK results needed before a WARNING or CRITICAL check
+// changes back to OK. Until then the check keeps its previous status and message. Defaults to 1.
+func WithSuccessThreshold(n int) CheckOption {
+	return func(c *Check) {
+		c.state.getDamping().successThreshold = n
+	}
+}
+
+// WithFlapDetection holds the check in WARNING while the results of the checker change between OK and
+// WARNING or CRITICAL more than maxTransitions times within the window
+func WithFlapDetection(window time.Duration, maxTransitions int) CheckOption {
+	return func(c *Check) {
+		d := c.state.getDamping()
+		d.flapWindow = window
+		d.flapMaxTransitions = maxTransitions
+	}
+}
+
+// getDamping returns the damping of the check state, creating it if the state has none
+func (s *CheckState) getDamping() *damping {
+	if s.damping == nil {
+		s.damping = &damping{}
+	}
+	return s.damping
+}
+
+// apply records a result of the checker and returns the status, message and status code the check should have,
+// given its current status. It returns false if the check should keep its current status, message and status code.
+func (d *damping) apply(now time.Time, current, status, message string, statusCode int) (string, string, int, bool) {
+	if status == StatusOK {
+		d.consecutiveSuccesses++
+		d.consecutiveFailures = 0
+	} else {
+		d.consecutiveFailures++
+		d.consecutiveSuccesses = 0
+	}
+
+	// the transitions are only needed, and only dropped once they are out of the window, with flap detection
+	if d.flapWindow > 0 && d.lastResult != "" && (d.lastResult == StatusOK) != (status == StatusOK) {
+		d.transitions = append(d.transitions, now)
+	}
+	d.lastResult = status
+
+	if d.isFlapping(now) {
+		return StatusWarning, fmt.Sprintf("check is flapping, %d status changes in the last %s", len(d.transitions), d.flapWindow), statusCode, true
+	}
+
+	switch {
+	case current == "":
+		// the first result is applied straight away, as there is no previous status to hold
+	case current != StatusOK && status == StatusOK && d.consecutiveSuccesses < max(d.successThreshold, 1):
+		return "", "", 0, false
+	case current == StatusOK && status != StatusOK && d.consecutiveFailures < max(d.failureThreshold, 1):
+		return "", "", 0, false
+	}
+
+	return status, message, statusCode, true
+}
+
+// isFlapping drops the transitions that are older than the flap window and returns true if there are
+// still more transitions than allowed
+func (d *damping) isFlapping(now time.Time) bool {
+	if d.flapWindow <= 0 {
+		return false
+	}
+
+	i := 0
+	for i < len(d.transitions) && now.Sub(d.transitions[i]) > d.flapWindow {
+		i++
+	}
+	d.transitions = d.transitions[i:]
+
+	return len(d.transitions) > d.flapMaxTransitions
+}
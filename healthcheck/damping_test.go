@@ -0,0 +1,129 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDamping(t *testing.T) {
+	checkerFunc := func(ctx context.Context, state *CheckState) error {
+		return nil
+	}
+
+	Convey("Given a check with a failure threshold of 3 and a success threshold of 2", t, func() {
+		check, err := NewCheck("check", checkerFunc, WithFailureThreshold(3), WithSuccessThreshold(2))
+		So(err, ShouldBeNil)
+
+		callbacks := 0
+		check.state.changeCallback = func() *sync.WaitGroup {
+			callbacks++
+			return &sync.WaitGroup{}
+		}
+
+		Convey("When the first result is CRITICAL", func() {
+			So(check.state.Update(StatusCritical, "down", 500), ShouldBeNil)
+
+			Convey("Then it is applied straight away", func() {
+				So(check.state.Status(), ShouldEqual, StatusCritical)
+				So(callbacks, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When an OK check has fewer consecutive failures than the threshold", func() {
+			So(check.state.Update(StatusOK, "ok", 200), ShouldBeNil)
+			So(check.state.Update(StatusCritical, "down", 500), ShouldBeNil)
+			So(check.state.Update(StatusWarning, "slow", 200), ShouldBeNil)
+
+			Convey("Then the check keeps its OK status and message", func() {
+				So(check.state.Status(), ShouldEqual, StatusOK)
+				So(check.state.Message(), ShouldEqual, "ok")
+				So(check.state.StatusCode(), ShouldEqual, 200)
+				So(callbacks, ShouldEqual, 1)
+			})
+
+			Convey("Then the last checked and failure times are still updated", func() {
+				So(*check.state.LastChecked(), ShouldEqual, *check.state.LastFailure())
+				So(check.state.LastSuccess().Before(*check.state.LastFailure()), ShouldBeTrue)
+			})
+
+			Convey("Then the failure count is reset by an OK result", func() {
+				So(check.state.Update(StatusOK, "ok again", 200), ShouldBeNil)
+				So(check.state.Update(StatusCritical, "down", 500), ShouldBeNil)
+				So(check.state.Update(StatusCritical, "down", 500), ShouldBeNil)
+				So(check.state.Status(), ShouldEqual, StatusOK)
+				So(check.state.Message(), ShouldEqual, "ok again")
+			})
+
+			Convey("Then the status changes once the threshold is reached", func() {
+				So(check.state.Update(StatusCritical, "down", 500), ShouldBeNil)
+				So(check.state.Status(), ShouldEqual, StatusCritical)
+				So(check.state.Message(), ShouldEqual, "down")
+				So(check.state.StatusCode(), ShouldEqual, 500)
+				So(callbacks, ShouldEqual, 2)
+
+				Convey("And a change between failing statuses is applied straight away", func() {
+					So(check.state.Update(StatusWarning, "slow", 200), ShouldBeNil)
+					So(check.state.Status(), ShouldEqual, StatusWarning)
+					So(callbacks, ShouldEqual, 3)
+				})
+
+				Convey("And the check only recovers after the success threshold is reached", func() {
+					So(check.state.Update(StatusOK, "ok", 200), ShouldBeNil)
+					So(check.state.Status(), ShouldEqual, StatusCritical)
+					So(check.state.Message(), ShouldEqual, "down")
+					So(check.state.Update(StatusOK, "ok", 200), ShouldBeNil)
+					So(check.state.Status(), ShouldEqual, StatusOK)
+					So(callbacks, ShouldEqual, 3)
+				})
+			})
+		})
+
+		Convey("When the results keep changing between OK and CRITICAL", func() {
+			for i := 0; i < 1000; i++ {
+				status := StatusOK
+				if i%2 == 1 {
+					status = StatusCritical
+				}
+				So(check.state.Update(status, "", 0), ShouldBeNil)
+			}
+
+			Convey("Then the changes are not kept, as flap detection is not enabled", func() {
+				So(check.state.damping.transitions, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given a check with flap detection allowing 2 changes in a minute", t, func() {
+		check, err := NewCheck("check", checkerFunc, WithFlapDetection(time.Minute, 2))
+		So(err, ShouldBeNil)
+
+		Convey("When the results alternate between OK and CRITICAL", func() {
+			So(check.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+			So(check.state.Update(StatusCritical, "down", 0), ShouldBeNil)
+			So(check.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+			So(check.state.Status(), ShouldEqual, StatusOK)
+			So(check.state.Update(StatusCritical, "down", 0), ShouldBeNil)
+
+			Convey("Then the check is held in WARNING", func() {
+				So(check.state.Status(), ShouldEqual, StatusWarning)
+				So(check.state.Message(), ShouldEqual, "check is flapping, 3 status changes in the last 1m0s")
+				So(check.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+				So(check.state.Status(), ShouldEqual, StatusWarning)
+			})
+
+			Convey("Then the check is released once the changes fall out of the window", func() {
+				d := check.state.damping
+				for i := range d.transitions {
+					d.transitions[i] = d.transitions[i].Add(-2 * time.Minute)
+				}
+				So(check.state.Update(StatusCritical, "down", 0), ShouldBeNil)
+				So(check.state.Status(), ShouldEqual, StatusCritical)
+				So(check.state.Message(), ShouldEqual, "down")
+			})
+		})
+	})
+}
@@ -0,0 +1,78 @@
+package healthcheck
+
+// Dependency represents the health check of a downstream app, as reported by its own health endpoint.
+// Dependencies are nested, so that the health of a whole chain of apps can be seen from the app at the top of it.
+type Dependency struct {
+	URL          string                 `json:"url"`
+	Status       string                 `json:"status"`
+	Version      VersionInfo            `json:"version"`
+	Checks       []*Check               `json:"checks"`
+	Dependencies map[string]*Dependency `json:"dependencies,omitempty"`
+	Truncated    bool                   `json:"truncated,omitempty"`
+	Cycle        bool                   `json:"cycle,omitempty"`
+}
+
+// NewDependency returns a new Dependency for the health check reported by the downstream app at the provided url.
+// The dependencies reported by the downstream app are nested up to maxDepth levels below this app (1 meaning no nesting);
+// any deeper dependencies are removed and their parent marked as truncated.
+// A dependency whose url has already been seen further up the tree is marked as a cycle and its dependencies removed.
+func NewDependency(url string, hc HealthCheck, maxDepth int) *Dependency {
+	dependency := &Dependency{
+		URL:          url,
+		Status:       hc.Status,
+		Version:      hc.Version,
+		Checks:       hc.Checks,
+		Dependencies: hc.Dependencies,
+	}
+	dependency.prune(map[string]bool{}, 1, maxDepth)
+	return dependency
+}
+
+// prune removes any dependencies that are deeper than maxDepth, or that have already been seen in the provided path
+func (d *Dependency) prune(path map[string]bool, depth, maxDepth int) {
+	if path[d.URL] {
+		d.Cycle = true
+		d.Dependencies = nil
+		return
+	}
+
+	if len(d.Dependencies) == 0 {
+		return
+	}
+
+	if depth >= maxDepth {
+		d.Truncated = true
+		d.Dependencies = nil
+		return
+	}
+
+	path[d.URL] = true
+	defer delete(path, d.URL)
+
+	pruned := make(map[string]*Dependency, len(d.Dependencies))
+	for name, dependency := range d.Dependencies {
+		if dependency == nil {
+			continue
+		}
+		copied := *dependency
+		copied.prune(path, depth+1, maxDepth)
+		pruned[name] = &copied
+	}
+	d.Dependencies = pruned
+}
+
+// getDependencies returns the dependencies reported by the checks, keyed by check name
+func (hc *HealthCheck) getDependencies() map[string]*Dependency {
+	var dependencies map[string]*Dependency
+	for _, check := range hc.Checks {
+		dependency := check.state.Dependency()
+		if dependency == nil {
+			continue
+		}
+		if dependencies == nil {
+			dependencies = map[string]*Dependency{}
+		}
+		dependencies[check.state.Name()] = dependency
+	}
+	return dependencies
+}
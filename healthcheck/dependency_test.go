@@ -0,0 +1,122 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewDependency(t *testing.T) {
+	Convey("Given a downstream health check with nested dependencies 3 levels deep", t, func() {
+		hc := HealthCheck{
+			Status:  StatusWarning,
+			Version: testVersion,
+			Checks:  []*Check{},
+			Dependencies: map[string]*Dependency{
+				"dataset api": {
+					URL:    "http://dataset-api/health",
+					Status: StatusOK,
+					Dependencies: map[string]*Dependency{
+						"zebedee": {
+							URL:    "http://zebedee/health",
+							Status: StatusOK,
+							Dependencies: map[string]*Dependency{
+								"dataset api": {URL: "http://dataset-api/health", Status: StatusOK},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		Convey("When a dependency is created with a max depth of 1", func() {
+			dependency := NewDependency("http://frontend/health", hc, 1)
+
+			Convey("Then the downstream dependencies are removed and the dependency is marked as truncated", func() {
+				So(dependency.URL, ShouldEqual, "http://frontend/health")
+				So(dependency.Status, ShouldEqual, StatusWarning)
+				So(dependency.Version, ShouldResemble, testVersion)
+				So(dependency.Dependencies, ShouldBeNil)
+				So(dependency.Truncated, ShouldBeTrue)
+			})
+
+			Convey("Then the original health check is not modified", func() {
+				So(hc.Dependencies, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a dependency is created with a max depth of 2", func() {
+			dependency := NewDependency("http://frontend/health", hc, 2)
+
+			Convey("Then only the first level of downstream dependencies is kept", func() {
+				So(dependency.Truncated, ShouldBeFalse)
+				So(dependency.Dependencies, ShouldContainKey, "dataset api")
+				So(dependency.Dependencies["dataset api"].Dependencies, ShouldBeNil)
+				So(dependency.Dependencies["dataset api"].Truncated, ShouldBeTrue)
+			})
+
+			Convey("Then the original health check is not modified", func() {
+				So(hc.Dependencies["dataset api"].Dependencies, ShouldHaveLength, 1)
+				So(hc.Dependencies["dataset api"].Truncated, ShouldBeFalse)
+			})
+		})
+
+		Convey("When a dependency is created with a max depth that is large enough for the whole tree", func() {
+			dependency := NewDependency("http://frontend/health", hc, 10)
+
+			Convey("Then the dependency that has already been seen further up the tree is marked as a cycle", func() {
+				zebedee := dependency.Dependencies["dataset api"].Dependencies["zebedee"]
+				So(zebedee.Cycle, ShouldBeFalse)
+				So(zebedee.Dependencies["dataset api"].Cycle, ShouldBeTrue)
+				So(zebedee.Dependencies["dataset api"].Dependencies, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestHandlerDependencies(t *testing.T) {
+	t0 := time.Now().UTC()
+
+	Convey("Given a healthcheck with a check that has a dependency and a check that does not", t, func() {
+		hc := getTestHealthCheck(t0, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{
+			{name: "dataset api", status: StatusOK, lastChecked: &t0, lastSuccess: &t0},
+			{name: "mongodb", status: StatusOK, lastChecked: &t0, lastSuccess: &t0},
+		}, true)
+		hc.Checks[0].state.SetDependency(&Dependency{URL: "http://dataset-api/health", Status: StatusOK, Checks: []*Check{}})
+
+		Convey("When the handler is called", func() {
+			w := httptest.NewRecorder()
+			hc.Handler(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			Convey("Then the response includes the dependency keyed by check name", func() {
+				var resp HealthCheck
+				err := json.Unmarshal(w.Body.Bytes(), &resp)
+				So(err, ShouldBeNil)
+				So(resp.Dependencies, ShouldHaveLength, 1)
+				So(resp.Dependencies["dataset api"].URL, ShouldEqual, "http://dataset-api/health")
+				So(resp.Dependencies["dataset api"].Status, ShouldEqual, StatusOK)
+			})
+		})
+	})
+
+	Convey("Given a healthcheck without any dependencies", t, func() {
+		hc := getTestHealthCheck(t0, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{
+			{name: "mongodb", status: StatusOK, lastChecked: &t0, lastSuccess: &t0},
+		}, true)
+
+		Convey("When the handler is called", func() {
+			w := httptest.NewRecorder()
+			hc.Handler(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			Convey("Then the response does not include the dependencies field", func() {
+				So(w.Body.String(), ShouldNotContainSubstring, "dependencies")
+			})
+		})
+	})
+}
@@ -0,0 +1,290 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// defaultStuckSubscriberTimeout is how long a subscriber may take to handle a health update before it is considered stuck,
+// if no timeout is provided
+const defaultStuckSubscriberTimeout = 10 * time.Second
+
+// SubscriberStats represents the statistics of the health updates sent to a subscriber
+type SubscriberStats struct {
+	Subscriber Subscriber
+	// Delivered is the number of health updates the subscriber has handled
+	Delivered uint64
+	// Coalesced is the number of health updates that were replaced by a later one before the subscriber could handle them
+	Coalesced uint64
+	// Stuck is true if the subscriber has been handling a health update for longer than the stuck subscriber timeout
+	Stuck bool
+	// BusyFor is how long the subscriber has been handling the current health update, if any
+	BusyFor time.Duration
+}
+
+// notificationCounters are the counters of the health updates sent to subscribers
+type notificationCounters struct {
+	delivered uint64
+	coalesced uint64
+	dropped   uint64
+}
+
+// dispatcher sends the health updates of a subscriber, in order, from a single goroutine.
+// Health updates that are queued while the subscriber is busy are coalesced, so that only the latest one is sent.
+type dispatcher struct {
+	subscriber   Subscriber
+	clock        Clock
+	stuckTimeout time.Duration
+	mutex        *sync.Mutex
+	status       string
+	queued       bool
+	waitGroups   []*sync.WaitGroup
+	busySince    time.Time
+	loggedBusy   time.Time
+	stuckTimer   Timer
+	counters     notificationCounters
+	pending      chan struct{}
+	stop         chan struct{}
+}
+
+// WithStuckSubscriberTimeout sets how long a subscriber may take to handle a health update before it is considered stuck
+func WithStuckSubscriberTimeout(timeout time.Duration) Option {
+	return func(hc *HealthCheck) {
+		hc.stuckSubscriberTimeout = timeout
+	}
+}
+
+// newDispatcher returns a pointer to a new instantiated dispatcher, which starts sending the health updates of the subscriber
+func newDispatcher(subscriber Subscriber, clock Clock, stuckTimeout time.Duration) *dispatcher {
+	d := &dispatcher{
+		subscriber:   subscriber,
+		clock:        clock,
+		stuckTimeout: stuckTimeout,
+		mutex:        &sync.Mutex{},
+		pending:      make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// enqueue queues the provided status to be sent to the subscriber, replacing any status that has not been sent yet.
+// The provided wait group is done once the status, or a later one, has been handled by the subscriber.
+func (d *dispatcher) enqueue(status string, wg *sync.WaitGroup) {
+	d.mutex.Lock()
+	if d.queued {
+		d.counters.coalesced++
+	}
+	d.status = status
+	d.queued = true
+	d.waitGroups = append(d.waitGroups, wg)
+	d.mutex.Unlock()
+
+	select {
+	case d.pending <- struct{}{}:
+	default:
+	}
+}
+
+// run sends the queued statuses to the subscriber until the dispatcher is closed
+func (d *dispatcher) run() {
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-d.pending:
+		}
+
+		d.mutex.Lock()
+		if !d.queued {
+			d.mutex.Unlock()
+			continue
+		}
+		status := d.status
+		waitGroups := d.waitGroups
+		d.queued = false
+		d.waitGroups = nil
+		d.busySince = d.clock.Now()
+		d.scheduleStuckCheck()
+		d.mutex.Unlock()
+
+		d.subscriber.OnHealthUpdate(status)
+
+		d.mutex.Lock()
+		d.busySince = time.Time{}
+		d.loggedBusy = time.Time{}
+		d.stuckTimer.Stop()
+		d.stuckTimer = nil
+		d.counters.delivered++
+		d.mutex.Unlock()
+
+		for _, wg := range waitGroups {
+			wg.Done()
+		}
+	}
+}
+
+// scheduleStuckCheck schedules the subscriber to be checked for being stuck once it has been handling the current
+// health update for another stuck timeout. The caller must hold the mutex.
+func (d *dispatcher) scheduleStuckCheck() {
+	delivered := d.counters.delivered
+	// a subscriber is only stuck once it has been busy for strictly longer than the stuck timeout
+	d.stuckTimer = d.clock.AfterFunc(d.stuckTimeout+time.Nanosecond, func() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+
+		// the health update may have been handled, and another one started, while waiting for the mutex
+		if d.busySince.IsZero() || d.counters.delivered != delivered {
+			return
+		}
+		d.logIfStuck(context.Background(), d.clock.Now())
+		d.scheduleStuckCheck()
+	})
+}
+
+// close stops the dispatcher, dropping any status that has not been sent yet
+func (d *dispatcher) close() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	close(d.stop)
+	if d.queued {
+		d.counters.dropped++
+		d.queued = false
+	}
+	for _, wg := range d.waitGroups {
+		wg.Done()
+	}
+	d.waitGroups = nil
+}
+
+// stats returns the statistics of the dispatcher, given the timeout after which a subscriber is considered stuck
+func (d *dispatcher) stats(now time.Time, stuckTimeout time.Duration) SubscriberStats {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	stats := SubscriberStats{
+		Subscriber: d.subscriber,
+		Delivered:  d.counters.delivered,
+		Coalesced:  d.counters.coalesced,
+	}
+	if !d.busySince.IsZero() {
+		stats.BusyFor = now.Sub(d.busySince)
+		stats.Stuck = stats.BusyFor > stuckTimeout
+	}
+	return stats
+}
+
+// logIfStuck logs a warning if the subscriber is stuck, once for every time it has been busy for another stuck timeout.
+// The caller must hold the mutex.
+func (d *dispatcher) logIfStuck(ctx context.Context, now time.Time) {
+	if d.busySince.IsZero() || now.Sub(d.busySince) <= d.stuckTimeout {
+		return
+	}
+	if !d.loggedBusy.IsZero() && now.Sub(d.loggedBusy) <= d.stuckTimeout {
+		return
+	}
+	d.loggedBusy = now
+
+	log.Warn(ctx, "subscriber is stuck handling a health update", log.Data{
+		"subscriber": fmt.Sprintf("%T", d.subscriber),
+		"busy_for":   now.Sub(d.busySince).String(),
+		"coalesced":  d.counters.coalesced,
+	})
+}
+
+// getStuckSubscriberTimeout returns the timeout after which a subscriber is considered stuck
+func (hc *HealthCheck) getStuckSubscriberTimeout() time.Duration {
+	if hc.stuckSubscriberTimeout > 0 {
+		return hc.stuckSubscriberTimeout
+	}
+	return defaultStuckSubscriberTimeout
+}
+
+// dispatch queues the provided status to be sent to the subscriber, creating its dispatcher if it does not have one yet.
+// The caller must hold the subsMutex.
+func (hc *HealthCheck) dispatch(s Subscriber, status string, wg *sync.WaitGroup) {
+	if hc.dispatchers == nil {
+		hc.dispatchers = map[Subscriber]*dispatcher{}
+	}
+	d, ok := hc.dispatchers[s]
+	if !ok {
+		d = newDispatcher(s, hc.getClock(), hc.getStuckSubscriberTimeout())
+		hc.dispatchers[s] = d
+	}
+
+	wg.Add(1)
+	d.enqueue(status, wg)
+}
+
+// removeSubscriber removes the subscriber and stops its dispatcher.
+// The caller must hold the subsMutex.
+func (hc *HealthCheck) removeSubscriber(s Subscriber) {
+	delete(hc.subscribers, s)
+	hc.closeDispatcher(s)
+}
+
+// closeDispatchers stops the dispatchers of all the subscribers, which are kept subscribed.
+// A new dispatcher is created for a subscriber on its next health update.
+// The caller must hold the subsMutex.
+func (hc *HealthCheck) closeDispatchers() {
+	for s := range hc.dispatchers {
+		hc.closeDispatcher(s)
+	}
+}
+
+// closeDispatcher stops the dispatcher of the subscriber, if any, keeping its counters for the metrics.
+// The caller must hold the subsMutex.
+func (hc *HealthCheck) closeDispatcher(s Subscriber) {
+	d, ok := hc.dispatchers[s]
+	if !ok {
+		return
+	}
+	delete(hc.dispatchers, s)
+	d.close()
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	hc.removedCounters.delivered += d.counters.delivered
+	hc.removedCounters.coalesced += d.counters.coalesced
+	hc.removedCounters.dropped += d.counters.dropped
+}
+
+// SubscriberStats returns the statistics of the health updates sent to every subscriber that has been notified at least once
+func (hc *HealthCheck) SubscriberStats() []SubscriberStats {
+	hc.subsMutex.Lock()
+	defer hc.subsMutex.Unlock()
+
+	now := hc.getClock().Now()
+	stats := make([]SubscriberStats, 0, len(hc.dispatchers))
+	for _, d := range hc.dispatchers {
+		stats = append(stats, d.stats(now, hc.getStuckSubscriberTimeout()))
+	}
+	return stats
+}
+
+// getNotificationCounters returns the total counters of the health updates sent to all subscribers, including removed ones,
+// the number of subscribers and the number of subscribers that are stuck
+func (hc *HealthCheck) getNotificationCounters() (notificationCounters, int, int) {
+	hc.subsMutex.Lock()
+	defer hc.subsMutex.Unlock()
+
+	now := hc.getClock().Now()
+	counters := hc.removedCounters
+	stuck := 0
+	for _, d := range hc.dispatchers {
+		d.mutex.Lock()
+		counters.delivered += d.counters.delivered
+		counters.coalesced += d.counters.coalesced
+		counters.dropped += d.counters.dropped
+		if !d.busySince.IsZero() && now.Sub(d.busySince) > hc.getStuckSubscriberTimeout() {
+			stuck++
+		}
+		d.mutex.Unlock()
+	}
+	return counters, len(hc.subscribers), stuck
+}
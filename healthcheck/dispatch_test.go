@@ -0,0 +1,200 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck/mock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// blockingSubscriber is a subscriber that records the statuses it is notified of, and blocks until it is released
+type blockingSubscriber struct {
+	mutex    sync.Mutex
+	statuses []string
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func newBlockingSubscriber() *blockingSubscriber {
+	return &blockingSubscriber{
+		started: make(chan struct{}, 10),
+		release: make(chan struct{}),
+	}
+}
+
+func (s *blockingSubscriber) OnHealthUpdate(status string) {
+	s.started <- struct{}{}
+	<-s.release
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.statuses = append(s.statuses, status)
+}
+
+func (s *blockingSubscriber) getStatuses() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.statuses...)
+}
+
+// waitForStart fails if the subscriber does not start handling a health update within a second
+func (s *blockingSubscriber) waitForStart() {
+	select {
+	case <-s.started:
+	case <-time.After(time.Second):
+		So("the subscriber was not notified", ShouldBeEmpty)
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	checkerFunc := func(ctx context.Context, state *CheckState) error {
+		return nil
+	}
+
+	Convey("Given a Health Check with a check that is OK", t, func() {
+		hc := New(version, criticalTimeout, interval)
+		c, err := hc.AddAndGetCheck("check", checkerFunc)
+		So(err, ShouldBeNil)
+		So(c.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+
+		Convey("When a subscriber is notified of several state changes", func() {
+			var mutex sync.Mutex
+			statuses := []string{}
+			s := &mock.SubscriberMock{
+				OnHealthUpdateFunc: func(status string) {
+					mutex.Lock()
+					defer mutex.Unlock()
+					statuses = append(statuses, status)
+				},
+			}
+			hc.Subscribe(s, c)
+
+			for _, status := range []string{StatusWarning, StatusOK, StatusWarning} {
+				c.state.mutex.Lock()
+				c.state.status = status
+				c.state.mutex.Unlock()
+				hc.checkChangeCallback(c)().Wait()
+			}
+
+			Convey("Then the subscriber is notified of every status, in order", func() {
+				mutex.Lock()
+				defer mutex.Unlock()
+				So(statuses, ShouldResemble, []string{StatusWarning, StatusOK, StatusWarning})
+			})
+		})
+
+		Convey("When a subscriber is busy while the state changes several times", func() {
+			s := newBlockingSubscriber()
+			hc.Subscribe(s, c)
+
+			wg1 := hc.healthChangeCallback()
+			s.waitForStart()
+
+			So(c.state.Update(StatusWarning, "slow", 0), ShouldBeNil)
+			So(c.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+			So(c.state.Update(StatusWarning, "slow", 0), ShouldBeNil)
+			wg2 := hc.healthChangeCallback()
+
+			Convey("Then the notifications are coalesced, and the subscriber is only notified of the latest status", func() {
+				close(s.release)
+				wg1.Wait()
+				wg2.Wait()
+				So(s.getStatuses(), ShouldResemble, []string{StatusOK, StatusWarning})
+
+				stats := hc.SubscriberStats()
+				So(stats, ShouldHaveLength, 1)
+				So(stats[0].Subscriber, ShouldEqual, s)
+				So(stats[0].Delivered, ShouldEqual, 2)
+				So(stats[0].Coalesced, ShouldEqual, 3)
+				So(stats[0].Stuck, ShouldBeFalse)
+			})
+
+			Convey("Then the pending notification is dropped when the subscriber is unsubscribed", func() {
+				hc.UnsubscribeAll(s)
+				wg2.Wait()
+				close(s.release)
+				wg1.Wait()
+
+				So(hc.SubscriberStats(), ShouldBeEmpty)
+				counters, subscribers, _ := hc.getNotificationCounters()
+				So(subscribers, ShouldEqual, 0)
+				So(counters.dropped, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When a subscriber has been handling a health update for longer than the stuck subscriber timeout", func() {
+			WithStuckSubscriberTimeout(time.Millisecond)(&hc)
+			s := newBlockingSubscriber()
+			defer close(s.release)
+			hc.Subscribe(s, c)
+
+			hc.healthChangeCallback()
+			s.waitForStart()
+			time.Sleep(10 * time.Millisecond)
+			hc.healthChangeCallback()
+
+			Convey("Then it is reported as stuck", func() {
+				stats := hc.SubscriberStats()
+				So(stats, ShouldHaveLength, 1)
+				So(stats[0].Stuck, ShouldBeTrue)
+				So(stats[0].BusyFor, ShouldBeGreaterThanOrEqualTo, 10*time.Millisecond)
+
+				w := httptest.NewRecorder()
+				hc.MetricsHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+				body := w.Body.String()
+				So(body, ShouldContainSubstring, "healthcheck_subscribers 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_subscribers_stuck 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_subscriber_notifications_coalesced_total 0\n")
+			})
+		})
+
+		Convey("When a subscriber keeps handling a single health update for longer than the stuck subscriber timeout", func() {
+			WithStuckSubscriberTimeout(time.Millisecond)(&hc)
+			s := newBlockingSubscriber()
+			defer close(s.release)
+			hc.Subscribe(s, c)
+
+			hc.healthChangeCallback()
+			s.waitForStart()
+			time.Sleep(10 * time.Millisecond)
+
+			Convey("Then it is logged as stuck without waiting for another health update", func() {
+				hc.subsMutex.Lock()
+				d := hc.dispatchers[s]
+				hc.subsMutex.Unlock()
+
+				d.mutex.Lock()
+				defer d.mutex.Unlock()
+				So(d.loggedBusy, ShouldNotBeZeroValue)
+			})
+		})
+
+		Convey("When the health check is started, a subscriber is notified, and the health check is stopped", func() {
+			So(hc.Start(context.Background()), ShouldBeNil)
+			s := newBlockingSubscriber()
+			close(s.release)
+			hc.Subscribe(s, c)
+			hc.healthChangeCallback().Wait()
+			So(hc.Stop(), ShouldBeNil)
+
+			Convey("Then the dispatcher of the subscriber is stopped, keeping its counters", func() {
+				So(hc.SubscriberStats(), ShouldBeEmpty)
+				counters, subscribers, _ := hc.getNotificationCounters()
+				So(subscribers, ShouldEqual, 1)
+				So(counters.delivered, ShouldEqual, 1)
+			})
+
+			Convey("Then the subscriber is notified again once the health check is restarted", func() {
+				So(hc.Start(context.Background()), ShouldBeNil)
+				defer hc.Stop()
+				hc.healthChangeCallback().Wait()
+				So(s.getStatuses(), ShouldHaveLength, 2)
+			})
+		})
+	})
+}
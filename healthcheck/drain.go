@@ -0,0 +1,57 @@
+package healthcheck
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// drainingReason is reported as the output of the IETF health response while the app is draining
+const drainingReason = "the app is shutting down"
+
+// Drain switches the app to CRITICAL, so that readiness fails and the load balancer stops sending traffic to it,
+// while the checks keep running. The app reports 'draining' in the health check response, and the subscribers and
+// watches are notified. Draining takes precedence over any override, and lasts until the health check is started
+// again after being stopped.
+func (hc *HealthCheck) Drain() {
+	hc.statusLock.Lock()
+	if hc.Draining {
+		hc.statusLock.Unlock()
+		return
+	}
+	hc.Draining = true
+	hc.statusLock.Unlock()
+
+	log.Info(context.Background(), "health check is draining")
+	hc.healthChangeCallback()
+}
+
+// IsDraining returns true if the app has started draining
+func (hc *HealthCheck) IsDraining() bool {
+	hc.statusLock.RLock()
+	defer hc.statusLock.RUnlock()
+
+	return hc.Draining
+}
+
+// BeginShutdown drains the app straight away, then stops the health check once the provided grace period has passed,
+// so that the load balancer has time to notice that the app is no longer ready.
+// The returned channel is closed once the health check has been stopped.
+func (hc *HealthCheck) BeginShutdown(grace time.Duration) <-chan struct{} {
+	hc.Drain()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		timer := hc.getClock().NewTimer(grace)
+		defer timer.Stop()
+		<-timer.C()
+
+		if err := hc.Stop(); err != nil {
+			log.Warn(context.Background(), "health check was not running at the end of the grace period", log.Data{"error": err.Error()})
+		}
+	}()
+	return stopped
+}
@@ -0,0 +1,117 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDrain(t *testing.T) {
+	Convey("Given a started Health Check with a check that is OK", t, func() {
+		var runs int32
+		checkerFunc := func(ctx context.Context, state *CheckState) error {
+			atomic.AddInt32(&runs, 1)
+			return state.Update(StatusOK, "ok", 0)
+		}
+
+		hc := New(version, criticalTimeout, 10*time.Millisecond)
+		c, err := hc.AddAndGetCheck("check", checkerFunc)
+		So(err, ShouldBeNil)
+		s, statuses := notifiedStatuses()
+		hc.Subscribe(s, c)
+
+		hc.Start(context.Background())
+		waitForAppStatus(&hc, StatusOK)
+
+		Convey("When the app is drained", func() {
+			hc.Drain()
+			defer hc.Stop()
+
+			Convey("Then the app is CRITICAL and reports that it is draining", func() {
+				So(hc.IsDraining(), ShouldBeTrue)
+
+				w := httptest.NewRecorder()
+				hc.Handler(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+
+				resp := map[string]interface{}{}
+				So(json.Unmarshal(w.Body.Bytes(), &resp), ShouldBeNil)
+				So(resp["status"], ShouldEqual, StatusCritical)
+				So(resp["draining"], ShouldEqual, true)
+			})
+
+			Convey("Then readiness fails, but liveness does not", func() {
+				w := httptest.NewRecorder()
+				hc.ReadinessHandler(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+				So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+
+				w = httptest.NewRecorder()
+				hc.LivenessHandler(w, httptest.NewRequest(http.MethodGet, "/live", nil))
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+
+			Convey("Then the subscribers are notified", func() {
+				notified := waitForNotifications(statuses, 2)
+				So(notified[len(notified)-1], ShouldEqual, StatusCritical)
+			})
+
+			Convey("Then an override does not undo it", func() {
+				So(hc.SetOverride(StatusOK, "all good", 0), ShouldBeNil)
+				So(hc.GetChecksStatus(), ShouldEqual, StatusCritical)
+			})
+
+			Convey("Then the checks keep running", func() {
+				before := atomic.LoadInt32(&runs)
+				time.Sleep(50 * time.Millisecond)
+				So(atomic.LoadInt32(&runs), ShouldBeGreaterThan, before)
+			})
+		})
+
+		Convey("When the shutdown begins with a grace period", func() {
+			start := time.Now()
+			stopped := hc.BeginShutdown(50 * time.Millisecond)
+
+			Convey("Then the app is draining straight away, and stopped once the grace period has passed", func() {
+				So(hc.IsDraining(), ShouldBeTrue)
+
+				select {
+				case <-stopped:
+				case <-time.After(time.Second):
+					So("the health check was not stopped", ShouldBeEmpty)
+				}
+				So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+
+				before := atomic.LoadInt32(&runs)
+				time.Sleep(50 * time.Millisecond)
+				So(atomic.LoadInt32(&runs), ShouldEqual, before)
+			})
+
+			Convey("Then the app is no longer draining once it is restarted after being stopped", func() {
+				<-stopped
+				So(hc.Start(context.Background()), ShouldBeNil)
+				defer hc.Stop()
+
+				So(hc.IsDraining(), ShouldBeFalse)
+				waitForAppStatus(&hc, StatusOK)
+
+				w := httptest.NewRecorder()
+				hc.Handler(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				resp := map[string]interface{}{}
+				So(json.Unmarshal(w.Body.Bytes(), &resp), ShouldBeNil)
+				So(resp["status"], ShouldEqual, StatusOK)
+				So(resp, ShouldNotContainKey, "draining")
+
+				notified := waitForNotifications(statuses, 3)
+				So(notified[len(notified)-1], ShouldEqual, StatusOK)
+			})
+		})
+	})
+}
@@ -0,0 +1,98 @@
+package healthcheck
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// GroupSummary represents the status of a group of checks, i.e. the checks with the same tag, in the health check response
+type GroupSummary struct {
+	Status string   `json:"status"`
+	Checks []string `json:"checks"`
+}
+
+// WithTags tags the check with the groups it belongs to, e.g. "storage", "messaging" or "upstream-apis"
+func WithTags(tags ...string) CheckOption {
+	return func(c *Check) {
+		c.tags = append(c.tags, tags...)
+	}
+}
+
+// Tags gets the tags of the check
+func (c *Check) Tags() []string {
+	return append([]string{}, c.tags...)
+}
+
+// hasTag returns true if the check has the provided tag
+func (c *Check) hasTag(tag string) bool {
+	for _, t := range c.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupStatus returns the accumulated status of the checks tagged with the provided group in a thread-safe way.
+// An error is returned if no check has that tag.
+func (hc *HealthCheck) GroupStatus(group string) (string, error) {
+	hc.statusLock.Lock()
+	defer hc.statusLock.Unlock()
+
+	checks := hc.getGroupChecks(group)
+	if len(checks) == 0 {
+		return "", fmt.Errorf("group not found: %s", group)
+	}
+	return hc.getChecksStatus(checks), nil
+}
+
+// GroupHandler returns a handler that responds to an http request with the health of the checks tagged with the
+// provided group only, in the same way as Handler does for the whole app
+func (hc *HealthCheck) GroupHandler(group string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		query, err := parseHealthQuery(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		query.group = group
+		hc.serveHealth(w, req, query)
+	}
+}
+
+// getGroupChecks returns the checks tagged with the provided group. The caller must hold the statusLock.
+func (hc *HealthCheck) getGroupChecks(group string) []*Check {
+	var checks []*Check
+	for _, check := range hc.Checks {
+		if check.hasTag(group) {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// getGroups returns the summary of every group of checks, keyed by group name, or nil if no check has been tagged.
+// The caller must hold the statusLock.
+func (hc *HealthCheck) getGroups() map[string]*GroupSummary {
+	var groups map[string]*GroupSummary
+	for _, check := range hc.Checks {
+		for _, tag := range check.tags {
+			if _, ok := groups[tag]; ok {
+				continue
+			}
+			if groups == nil {
+				groups = map[string]*GroupSummary{}
+			}
+			checks := hc.getGroupChecks(tag)
+			summary := &GroupSummary{
+				Status: hc.getChecksStatus(checks),
+				Checks: make([]string, 0, len(checks)),
+			}
+			for _, c := range checks {
+				summary.Checks = append(summary.Checks, c.state.Name())
+			}
+			groups[tag] = summary
+		}
+	}
+	return groups
+}
@@ -0,0 +1,165 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGroups(t *testing.T) {
+	t0 := time.Now().UTC()
+	t20 := t0.Add(-20 * time.Minute) // 20 min ago
+
+	okState := CheckState{name: "mongodb", status: StatusOK, lastChecked: &t0, lastSuccess: &t0}
+	warningState := CheckState{name: "s3", status: StatusWarning, lastChecked: &t0, lastFailure: &t0}
+	criticalState := CheckState{name: "kafka", status: StatusCritical, lastChecked: &t0, lastFailure: &t0, criticalSince: &t20}
+
+	createGroupedHealthCheck := func() HealthCheck {
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{okState, warningState, criticalState}, true)
+		WithTags("storage")(hc.Checks[0])
+		WithTags("storage", "upstream-apis")(hc.Checks[1])
+		WithTags("messaging")(hc.Checks[2])
+		return hc
+	}
+
+	callHandler := func(handler http.HandlerFunc, target string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		var body map[string]interface{}
+		if w.Code != http.StatusNotFound {
+			So(json.Unmarshal(w.Body.Bytes(), &body), ShouldBeNil)
+		}
+		return w, body
+	}
+
+	checkNames := func(body map[string]interface{}) []string {
+		var names []string
+		for _, check := range body["checks"].([]interface{}) {
+			names = append(names, check.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	Convey("Given a check created with tags", t, func() {
+		check, err := NewCheck("check", func(ctx context.Context, state *CheckState) error { return nil },
+			WithTags("storage"), WithTags("upstream-apis"))
+		So(err, ShouldBeNil)
+
+		Convey("Then the check has every tag", func() {
+			So(check.Tags(), ShouldResemble, []string{"storage", "upstream-apis"})
+			So(check.hasTag("storage"), ShouldBeTrue)
+			So(check.hasTag("messaging"), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given a healthcheck with checks tagged with groups", t, func() {
+		hc := createGroupedHealthCheck()
+
+		Convey("Then the status of each group is the status of its checks", func() {
+			status, err := hc.GroupStatus("storage")
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, StatusWarning)
+
+			status, err = hc.GroupStatus("messaging")
+			So(err, ShouldBeNil)
+			So(status, ShouldEqual, StatusCritical)
+		})
+
+		Convey("Then the status of an unknown group cannot be returned", func() {
+			_, err := hc.GroupStatus("unknown")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("When the handler is called", func() {
+			w, body := callHandler(hc.Handler, "/health")
+
+			Convey("Then the response includes every check and a summary of every group", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(body["status"], ShouldEqual, StatusCritical)
+				So(checkNames(body), ShouldResemble, []string{"mongodb", "s3", "kafka"})
+				So(body["groups"], ShouldResemble, map[string]interface{}{
+					"storage":       map[string]interface{}{"status": StatusWarning, "checks": []interface{}{"mongodb", "s3"}},
+					"upstream-apis": map[string]interface{}{"status": StatusWarning, "checks": []interface{}{"s3"}},
+					"messaging":     map[string]interface{}{"status": StatusCritical, "checks": []interface{}{"kafka"}},
+				})
+			})
+		})
+
+		Convey("When the handler is called for a group", func() {
+			w, body := callHandler(hc.Handler, "/health?group=storage")
+
+			Convey("Then the response only includes the checks of that group, with their status", func() {
+				So(w.Code, ShouldEqual, http.StatusTooManyRequests)
+				So(body["status"], ShouldEqual, StatusWarning)
+				So(checkNames(body), ShouldResemble, []string{"mongodb", "s3"})
+				So(body, ShouldNotContainKey, "groups")
+			})
+
+			Convey("Then the app status is left untouched", func() {
+				So(hc.GetStatus(), ShouldEqual, StatusCritical)
+				So(hc.Checks, ShouldHaveLength, 3)
+			})
+		})
+
+		Convey("When the handler is called for an unknown group", func() {
+			w, _ := callHandler(hc.Handler, "/health?group=unknown")
+
+			Convey("Then a 404 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When the group handler is called", func() {
+			w, body := callHandler(hc.GroupHandler("messaging"), "/health/messaging")
+
+			Convey("Then the response only includes the checks of that group", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(body["status"], ShouldEqual, StatusCritical)
+				So(checkNames(body), ShouldResemble, []string{"kafka"})
+			})
+		})
+
+		Convey("When the group handler is called asking for the IETF format", func() {
+			req := httptest.NewRequest(http.MethodGet, "/health/storage", nil)
+			req.Header.Set("Accept", ietfContentType)
+			w := httptest.NewRecorder()
+			hc.GroupHandler("storage")(w, req)
+
+			var body map[string]interface{}
+			So(json.Unmarshal(w.Body.Bytes(), &body), ShouldBeNil)
+
+			Convey("Then the response only includes the checks of that group", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(body["status"], ShouldEqual, ietfStatusWarn)
+				So(body["checks"], ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a healthcheck without tagged checks", t, func() {
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{okState}, true)
+
+		Convey("Then the response does not include any groups", func() {
+			_, body := callHandler(hc.Handler, "/health")
+			So(body, ShouldNotContainKey, "groups")
+		})
+	})
+
+	Convey("Given a per-group aggregation policy for a group of tagged checks", t, func() {
+		hc := createGroupedHealthCheck()
+		WithAggregationPolicy(PerGroup(GroupPolicy{Name: "messaging", Policy: Quorum(0)}))(&hc)
+
+		Convey("Then the checks tagged with the group name are aggregated with the policy of the group", func() {
+			So(hc.isAppHealthy(), ShouldEqual, StatusWarning)
+		})
+	})
+}
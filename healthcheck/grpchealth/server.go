@@ -0,0 +1,139 @@
+package grpchealth
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the gRPC health checking protocol (grpc.health.v1.Health) on top of a HealthCheck.
+// The overall health of the app is reported for the empty service name, and named services report
+// the accumulated status of the checks they are registered with.
+// OK and WARNING are reported as SERVING, and CRITICAL as NOT_SERVING.
+type Server struct {
+	healthpb.UnimplementedHealthServer
+	hc       *healthcheck.HealthCheck
+	services map[string][]*healthcheck.Check
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithService registers a named service that reports the accumulated status of the provided checks.
+// A service registered without any checks reports the overall health of the app.
+func WithService(name string, checks ...*healthcheck.Check) Option {
+	return func(s *Server) {
+		s.services[name] = checks
+	}
+}
+
+// NewServer returns a pointer to a new instantiated Server backed by the provided health check,
+// which can be registered with a gRPC server using healthpb.RegisterHealthServer
+func NewServer(hc *healthcheck.HealthCheck, opts ...Option) *Server {
+	s := &Server{
+		hc:       hc,
+		services: map[string][]*healthcheck.Check{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Check returns the serving status of the requested service, or a NotFound error if the service is unknown
+func (s *Server) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	servingStatus, ok := s.getServingStatus(req.GetService())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service: %s", req.GetService())
+	}
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+// Watch sends the serving status of the requested service straight away, and then every time it changes,
+// until the client cancels the call. An unknown service is reported as SERVICE_UNKNOWN.
+func (s *Server) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	service := req.GetService()
+	ctx := stream.Context()
+
+	checks, ok := s.services[service]
+	if !ok && service != "" {
+		if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVICE_UNKNOWN}); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	// subscribe before getting the current status, so that no change is missed
+	w := newWatcher()
+	if len(checks) == 0 {
+		s.hc.SubscribeAll(w)
+	} else {
+		s.hc.Subscribe(w, checks...)
+	}
+	defer s.hc.UnsubscribeAll(w)
+
+	servingStatus, _ := s.getServingStatus(service)
+	if err := stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-w.updates:
+			newStatus, _ := s.getServingStatus(service)
+			if newStatus == servingStatus {
+				continue
+			}
+			servingStatus = newStatus
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// getServingStatus returns the serving status of the provided service, and false if the service is unknown
+func (s *Server) getServingStatus(service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	checks, ok := s.services[service]
+	if !ok && service != "" {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, false
+	}
+	return toServingStatus(s.hc.GetChecksStatus(checks...)), true
+}
+
+// toServingStatus returns the gRPC serving status for the provided health check status
+func toServingStatus(status string) healthpb.HealthCheckResponse_ServingStatus {
+	switch status {
+	case healthcheck.StatusOK, healthcheck.StatusWarning:
+		return healthpb.HealthCheckResponse_SERVING
+	default:
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+}
+
+// watcher is a healthcheck.Subscriber that signals a Watch call every time the status of its checks may have changed
+type watcher struct {
+	updates chan struct{}
+}
+
+// newWatcher returns a pointer to a new instantiated watcher
+func newWatcher() *watcher {
+	return &watcher{
+		updates: make(chan struct{}, 1),
+	}
+}
+
+// OnHealthUpdate signals the Watch call without blocking. Signals are coalesced, as the Watch call
+// gets the latest status when it handles them.
+func (w *watcher) OnHealthUpdate(status string) {
+	select {
+	case w.updates <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,222 @@
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var testVersion = healthcheck.VersionInfo{
+	BuildTime: time.Unix(0, 0),
+	Version:   "1.0.0",
+}
+
+// stateCapturer is a checker that reports an initial status and keeps the check state,
+// so that the tests can update it later
+type stateCapturer struct {
+	mutex         sync.Mutex
+	initialStatus string
+	state         *healthcheck.CheckState
+}
+
+func (c *stateCapturer) Check(ctx context.Context, state *healthcheck.CheckState) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.state = state
+	return state.Update(c.initialStatus, "initial status", 0)
+}
+
+func (c *stateCapturer) update(status string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	So(c.state.Update(status, "updated status", 0), ShouldBeNil)
+}
+
+// startServer serves the provided health server in-process and returns a client connected to it
+func startServer(server healthpb.HealthServer) (healthpb.HealthClient, func()) {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	So(err, ShouldBeNil)
+
+	return healthpb.NewHealthClient(conn), func() {
+		conn.Close()
+		grpcServer.Stop()
+	}
+}
+
+// waitForStatus waits for the health check status of the provided checks to become the expected one
+func waitForStatus(hc *healthcheck.HealthCheck, expected string, checks ...*healthcheck.Check) {
+	deadline := time.Now().Add(time.Second)
+	for hc.GetChecksStatus(checks...) != expected && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	So(hc.GetChecksStatus(checks...), ShouldEqual, expected)
+}
+
+func TestServer(t *testing.T) {
+	Convey("Given a started health check with an OK check and a CRITICAL check, served over gRPC", t, func() {
+		hc := healthcheck.New(testVersion, 0, time.Hour)
+
+		okChecker := &stateCapturer{initialStatus: healthcheck.StatusOK}
+		okCheck, err := hc.AddAndGetCheck("ok check", okChecker.Check)
+		So(err, ShouldBeNil)
+		criticalChecker := &stateCapturer{initialStatus: healthcheck.StatusCritical}
+		criticalCheck, err := hc.AddAndGetCheck("critical check", criticalChecker.Check)
+		So(err, ShouldBeNil)
+
+		hc.Start(context.Background())
+		defer hc.Stop()
+		waitForStatus(&hc, healthcheck.StatusCritical)
+
+		client, stop := startServer(NewServer(&hc,
+			WithService("api", okCheck),
+			WithService("worker", okCheck, criticalCheck),
+		))
+		defer stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		Convey("When Check is called for the overall health of the app", func() {
+			resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+
+			Convey("Then the app is reported as NOT_SERVING", func() {
+				So(err, ShouldBeNil)
+				So(resp.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_NOT_SERVING)
+			})
+		})
+
+		Convey("When Check is called for the named services", func() {
+			api, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "api"})
+			So(err, ShouldBeNil)
+			worker, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "worker"})
+			So(err, ShouldBeNil)
+
+			Convey("Then each service is reported according to its own checks", func() {
+				So(api.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_SERVING)
+				So(worker.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_NOT_SERVING)
+			})
+		})
+
+		Convey("When Check is called for an unknown service", func() {
+			_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+
+			Convey("Then a NotFound error is returned", func() {
+				So(status.Code(err), ShouldEqual, codes.NotFound)
+			})
+		})
+
+		Convey("When Watch is called for the overall health of the app", func() {
+			stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+			So(err, ShouldBeNil)
+
+			Convey("Then the current status is sent straight away", func() {
+				resp, err := stream.Recv()
+				So(err, ShouldBeNil)
+				So(resp.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_NOT_SERVING)
+
+				Convey("And a new status is sent when the CRITICAL check recovers", func() {
+					criticalChecker.update(healthcheck.StatusOK)
+
+					resp, err := stream.Recv()
+					So(err, ShouldBeNil)
+					So(resp.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_SERVING)
+				})
+			})
+		})
+
+		Convey("When Watch is called for a named service", func() {
+			stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "worker"})
+			So(err, ShouldBeNil)
+			resp, err := stream.Recv()
+			So(err, ShouldBeNil)
+			So(resp.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_NOT_SERVING)
+
+			Convey("Then no new status is sent while the serving status does not change", func() {
+				okChecker.update(healthcheck.StatusWarning)
+
+				Convey("And a new status is sent once the service recovers", func() {
+					criticalChecker.update(healthcheck.StatusOK)
+
+					resp, err := stream.Recv()
+					So(err, ShouldBeNil)
+					So(resp.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_SERVING)
+				})
+			})
+
+			Convey("Then the call ends when it is cancelled by the client", func() {
+				cancel()
+				_, err := stream.Recv()
+				So(status.Code(err), ShouldEqual, codes.Canceled)
+			})
+		})
+
+		Convey("When Watch is called for an unknown service", func() {
+			stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+			So(err, ShouldBeNil)
+
+			Convey("Then SERVICE_UNKNOWN is sent and the call is kept open", func() {
+				resp, err := stream.Recv()
+				So(err, ShouldBeNil)
+				So(resp.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_SERVICE_UNKNOWN)
+			})
+		})
+	})
+
+	Convey("Given a started health check with a CRITICAL check that has a critical timeout, served over gRPC", t, func() {
+		hc := healthcheck.New(testVersion, 0, time.Hour)
+
+		criticalChecker := &stateCapturer{initialStatus: healthcheck.StatusCritical}
+		_, err := hc.AddAndGetCheck("critical check", criticalChecker.Check, healthcheck.WithCriticalTimeout(200*time.Millisecond))
+		So(err, ShouldBeNil)
+
+		hc.Start(context.Background())
+		defer hc.Stop()
+		waitForStatus(&hc, healthcheck.StatusWarning)
+
+		client, stop := startServer(NewServer(&hc))
+		defer stop()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		Convey("When Watch is called for the overall health of the app", func() {
+			stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+			So(err, ShouldBeNil)
+
+			Convey("Then the app is reported as SERVING while the critical timeout has not expired", func() {
+				resp, err := stream.Recv()
+				So(err, ShouldBeNil)
+				So(resp.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_SERVING)
+
+				Convey("And NOT_SERVING is sent once it expires, without any new state of the check", func() {
+					resp, err := stream.Recv()
+					So(err, ShouldBeNil)
+					So(resp.GetStatus(), ShouldEqual, healthpb.HealthCheckResponse_NOT_SERVING)
+				})
+			})
+		})
+	})
+}
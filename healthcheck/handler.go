@@ -2,49 +2,116 @@ package healthcheck
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/ONSdigital/log.go/v2/log"
 )
 
-var minTime = time.Unix(0, 0)
-
-// Handler responds to an http request for the current health status
+// Handler responds to an http request for the current health status.
+// The response is in the format requested by the Accept header, or in the configured response format by default.
+// The following query parameters are supported, in which case the status is the status of the requested checks only:
+//   - group: the checks tagged with the provided group only
+//   - check: the checks with the provided comma separated names only
+//   - status: the checks with the provided comma separated statuses only, e.g. CRITICAL to list the failing checks
+//   - verbose: false to get the status only, without the checks
+//
+// A HEAD request only gets the status code.
 func (hc *HealthCheck) Handler(w http.ResponseWriter, req *http.Request) {
-	hc.statusLock.Lock()
-	defer hc.statusLock.Unlock()
+	query, err := parseHealthQuery(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	hc.serveHealth(w, req, query)
+}
 
-	now := time.Now().UTC()
+// serveHealth responds to an http request with the current health status of the app,
+// or of the checks matching the provided query
+func (hc *HealthCheck) serveHealth(w http.ResponseWriter, req *http.Request, query healthQuery) {
 	ctx := req.Context()
+	format := hc.getResponseFormat(req.Header.Get("Accept"))
 
-	newStatus := hc.getAppStatus(ctx)
-	hc.Status = newStatus
-	hc.Uptime = now.Sub(hc.StartTime) / time.Millisecond
+	build := func() (*healthResponse, error) {
+		return hc.buildResponse(ctx, query, format)
+	}
 
-	b, err := json.Marshal(hc)
+	var resp *healthResponse
+	var err error
+	if hc.responseCache != nil && !query.isFiltered() {
+		key := responseCacheKey{format: format, verbose: query.verbose}
+		resp, err = hc.responseCache.get(key, hc.getClock().Now(), build)
+	} else {
+		resp, err = build()
+	}
 	if err != nil {
-		log.Error(ctx, "failed to marshal json", err, log.Data{"health_check_response": hc})
+		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	resp.write(ctx, w, req)
+}
 
-	switch newStatus {
-	case StatusOK:
-		w.WriteHeader(http.StatusOK)
-	case StatusWarning:
-		w.WriteHeader(http.StatusTooManyRequests)
-	default:
-		w.WriteHeader(http.StatusInternalServerError)
+// buildResponse returns the response to a health check request with the provided query, in the provided format.
+// An error is returned if the query asks for checks that do not exist.
+func (hc *HealthCheck) buildResponse(ctx context.Context, query healthQuery, format ResponseFormat) (*healthResponse, error) {
+	hc.statusLock.Lock()
+	defer hc.statusLock.Unlock()
+
+	newStatus := hc.updateStatus(ctx)
+	hc.Dependencies = hc.getDependencies()
+	hc.Groups = hc.getGroups()
+
+	resp := hc
+	if query.isFiltered() {
+		checks, err := hc.filterChecks(query)
+		if err != nil {
+			return nil, err
+		}
+		newStatus = hc.getChecksStatus(checks)
+
+		// the response only describes the requested checks, the app health is left untouched
+		filtered := *hc
+		filtered.Status = newStatus
+		filtered.Checks = checks
+		filtered.Dependencies = filtered.getDependencies()
+		filtered.Groups = nil
+		resp = &filtered
 	}
 
-	_, err = w.Write(b)
-	if err != nil {
-		log.Error(ctx, "failed to write bytes for http response", err)
-		return
+	// the status of checks that are starting up is WARNING, unless it has been forced
+	_, forced := hc.getForcedStatus()
+	startingUp := !forced && hc.areChecksStartingUp(resp.Checks)
+
+	statusCode, headers := hc.getStatusCode(newStatus, startingUp, format)
+
+	var body interface{}
+	var contentType string
+	if format == FormatIETF {
+		contentType = ietfContentType
+		body = statusResponse{Status: toIETFStatus(newStatus)}
+		if query.verbose {
+			body = resp.getIETFResponse()
+		}
+	} else {
+		contentType = "application/json; charset=utf-8"
+		body = statusResponse{Status: newStatus}
+		if query.verbose {
+			body = resp
+		}
 	}
+
+	return newHealthResponse(ctx, contentType, statusCode, headers, body), nil
+}
+
+// updateStatus recalculates the app status and uptime, returning the new status.
+// The caller must hold the statusLock.
+func (hc *HealthCheck) updateStatus(ctx context.Context) string {
+	now := hc.getClock().Now().UTC()
+	newStatus := hc.getAppStatus(ctx)
+	hc.Status = newStatus
+	hc.Uptime = now.Sub(hc.StartTime) / time.Millisecond
+	return newStatus
 }
 
 // isAppStartingUp returns false when all clients have completed at least one check
@@ -54,6 +121,14 @@ func (hc *HealthCheck) isAppStartingUp() bool {
 
 func (hc *HealthCheck) areChecksStartingUp(checks []*Check) bool {
 	for _, check := range checks {
+		// informational checks do not affect the app health, so the app does not need to wait for them
+		if check.criticality == CriticalityInformational {
+			continue
+		}
+		// checks with a forced status do not need to wait for their checker either
+		if check.state.Override() != nil {
+			continue
+		}
 		if !check.hasRun() {
 			return true
 		}
@@ -63,6 +138,9 @@ func (hc *HealthCheck) areChecksStartingUp(checks []*Check) bool {
 
 // getAppStatus returns a status as string as to the overall current apps health based on its dependent apps health
 func (hc *HealthCheck) getAppStatus(ctx context.Context) string {
+	if status, ok := hc.getForcedStatus(); ok {
+		return status
+	}
 	if hc.isAppStartingUp() {
 		log.Warn(ctx, "a dependency is still starting up")
 		return StatusWarning
@@ -70,15 +148,30 @@ func (hc *HealthCheck) getAppStatus(ctx context.Context) string {
 	return hc.isAppHealthy()
 }
 
+// GetChecksStatus returns the accumulated status of the provided checks in a thread-safe way,
+// or the status of all the checks if none are provided. The status is WARNING while any of the checks has not run yet.
+func (hc *HealthCheck) GetChecksStatus(checks ...*Check) string {
+	hc.statusLock.Lock()
+	defer hc.statusLock.Unlock()
+
+	if len(checks) == 0 {
+		checks = hc.Checks
+	}
+	return hc.getChecksStatus(checks)
+}
+
 func (hc *HealthCheck) getChecksStatus(checks []*Check) string {
+	if status, ok := hc.getForcedStatus(); ok {
+		return status
+	}
 	if hc.areChecksStartingUp(checks) {
 		return StatusWarning
 	}
 	return hc.areChecksHealthy(checks)
 }
 
-// isAppHealthy checks individual Checks for their health then produces
-// and returns the 'worst' of those statuses as this app's health
+// isAppHealthy checks individual Checks for their health then combines those statuses into this app's health
+// with the aggregation policy, which by default returns the 'worst' of those statuses
 // (i.e. if any are StatusCritical, return that,
 //          else if any are StatusWarning, return that,
 //          else return StatusOK)
@@ -86,21 +179,39 @@ func (hc *HealthCheck) isAppHealthy() string {
 	return hc.areChecksHealthy(hc.Checks)
 }
 
+// areChecksHealthy combines the statuses of the provided checks with the aggregation policy, leaving out informational checks
 func (hc *HealthCheck) areChecksHealthy(checks []*Check) string {
-	status := StatusOK
+	results := make([]CheckResult, 0, len(checks))
 	for _, check := range checks {
-		checkStatus := hc.getCheckStatus(check)
-		if checkStatus == StatusCritical {
-			return StatusCritical
-		} else if checkStatus == StatusWarning {
-			status = StatusWarning
+		if check.criticality == CriticalityInformational && check.state.Override() == nil {
+			continue
 		}
+		results = append(results, CheckResult{
+			Name:   check.state.Name(),
+			Tags:   check.tags,
+			Status: hc.getCheckStatus(check),
+		})
 	}
-	return status
+	return hc.getAggregationPolicy().Aggregate(results)
 }
 
-// getCheckStatus returns a string for the status on an individual check
+// getCheckStatus returns a string for the status on an individual check,
+// limited according to the criticality of the check, unless the status has been forced with an override
 func (hc *HealthCheck) getCheckStatus(c *Check) string {
+	if override := c.state.Override(); override != nil {
+		return override.Status
+	}
+
+	switch c.criticality {
+	case CriticalityInformational:
+		return StatusOK
+	case CriticalityDegrading:
+		if c.state.Status() == StatusOK {
+			return StatusOK
+		}
+		return StatusWarning
+	}
+
 	switch c.state.Status() {
 	case StatusOK:
 		return StatusOK
@@ -108,27 +219,70 @@ func (hc *HealthCheck) getCheckStatus(c *Check) string {
 		return StatusWarning
 	default:
 
-		now := time.Now().UTC()
-		status := StatusWarning
-
-		// last success or minTime if nil. c should not be muted.
-		lastSuccess := c.state.LastSuccess()
-		if lastSuccess == nil {
-			lastSuccess = &minTime
+		// the check is only considered critical once it has been critical for longer than its critical timeout
+		criticalSince := c.state.CriticalSince()
+		if criticalSince == nil {
+			return StatusWarning
 		}
-
-		// Global state will be considered critical if check has been critical for longer
-		// than the first critical error since last success and the timeout has expired.
-		criticalTimeThreshold := hc.timeOfFirstCriticalError.Add(hc.criticalErrorTimeout)
-		if lastSuccess.Before(hc.timeOfFirstCriticalError) && now.After(criticalTimeThreshold) {
-			status = StatusCritical
+		if hc.getClock().Now().UTC().After(criticalSince.Add(hc.getCriticalTimeout(c))) {
+			return StatusCritical
 		}
+		return StatusWarning
+	}
+}
+
+// getCriticalTimeout returns the critical error timeout of the check, or the one of the health check if it has not been overridden
+func (hc *HealthCheck) getCriticalTimeout(c *Check) time.Duration {
+	if c.criticalTimeout > 0 {
+		return c.criticalTimeout
+	}
+	return hc.criticalErrorTimeout
+}
+
+// scheduleEscalation schedules the health to be re-evaluated once the next CRITICAL check that is still reported as
+// WARNING has been CRITICAL for longer than its critical timeout, so that subscribers, watches and cached responses are
+// notified of its escalation to CRITICAL even though the state of the check has not changed.
+// Nothing is scheduled once the health check has been stopped. The caller must hold the statusLock.
+func (hc *HealthCheck) scheduleEscalation() {
+	hc.stopEscalation()
+	if hc.lifecycle == lifecycleStopped {
+		return
+	}
 
-		// Set timestamp of first critical error to now if there has been a success since the previous value, or if this is the first one.
-		if lastSuccess.After(hc.timeOfFirstCriticalError) || hc.timeOfFirstCriticalError.IsZero() {
-			hc.timeOfFirstCriticalError = now
+	now := hc.getClock().Now().UTC()
+	var next time.Time
+	for _, check := range hc.Checks {
+		if check.criticality != CriticalityCritical || check.state.Override() != nil || check.state.Status() != StatusCritical {
+			continue
+		}
+		criticalSince := check.state.CriticalSince()
+		if criticalSince == nil {
+			continue
 		}
+		// the check is only escalated once the current time is strictly after the end of its critical timeout
+		escalation := criticalSince.Add(hc.getCriticalTimeout(check)).Add(time.Nanosecond)
+		if escalation.After(now) && (next.IsZero() || escalation.Before(next)) {
+			next = escalation
+		}
+	}
 
-		return status
+	if !next.IsZero() {
+		hc.escalationTimer = hc.getClock().AfterFunc(next.Sub(now), func() {
+			// the timer may have fired as the health check was being stopped
+			hc.statusLock.RLock()
+			stopped := hc.lifecycle == lifecycleStopped
+			hc.statusLock.RUnlock()
+			if !stopped {
+				hc.healthChangeCallback()
+			}
+		})
+	}
+}
+
+// stopEscalation stops any scheduled re-evaluation of the health. The caller must hold the statusLock.
+func (hc *HealthCheck) stopEscalation() {
+	if hc.escalationTimer != nil {
+		hc.escalationTimer.Stop()
+		hc.escalationTimer = nil
 	}
 }
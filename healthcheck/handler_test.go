@@ -30,6 +30,7 @@ func getTestHealthCheck(startTime time.Time, criticalErrTimeout time.Duration) H
 		criticalErrorTimeout: criticalErrTimeout,
 		tickers:              nil,
 		statusLock:           &sync.RWMutex{},
+		subsMutex:            &sync.Mutex{},
 	}
 }
 
@@ -177,79 +178,110 @@ func TestGetCheckStatus(t *testing.T) {
 	})
 
 	Convey("Given check status is failure", t, func() {
-		Convey("When check is without last successful state and time of first critical state"+
-			"is greater than the critical timeout", func() {
+		Convey("When the check has been critical for longer than the critical timeout", func() {
 			Convey("Then the returning status is critical", func() {
 				check := &Check{
 					state: &CheckState{
-						status: StatusCritical,
-						mutex:  &sync.RWMutex{},
+						status:        StatusCritical,
+						criticalSince: &t20,
+						mutex:         &sync.RWMutex{},
 					},
 				}
 
-				hc.timeOfFirstCriticalError = t20
-
 				status := hc.getCheckStatus(check)
 				So(status, ShouldEqual, StatusCritical)
-				So(hc.timeOfFirstCriticalError, ShouldEqual, t20)
+				So(check.state.CriticalSince(), ShouldResemble, &t20)
 			})
 		})
 
-		Convey("When check is without last successful state and time of first critical state"+
-			"is equal to the critical timeout", func() {
-
+		Convey("When the check has been critical for exactly the critical timeout", func() {
 			Convey("Then the returning status is critical", func() {
 				check := &Check{
 					state: &CheckState{
-						status: StatusCritical,
-						mutex:  &sync.RWMutex{},
+						status:        StatusCritical,
+						criticalSince: &t10,
+						mutex:         &sync.RWMutex{},
 					},
 				}
 
-				hc.timeOfFirstCriticalError = t10
-
 				status := hc.getCheckStatus(check)
 				So(status, ShouldEqual, StatusCritical)
-				So(hc.timeOfFirstCriticalError, ShouldEqual, t10)
+				So(check.state.CriticalSince(), ShouldResemble, &t10)
 			})
 		})
 
-		Convey("When check is without last successful state and time of first critical state"+
-			"is less than the critical timeout", func() {
+		Convey("When the check has been critical for less than the critical timeout", func() {
 			Convey("Then the returning status is warning", func() {
 				check := &Check{
 					state: &CheckState{
-						status: StatusCritical,
-						mutex:  &sync.RWMutex{},
+						status:        StatusCritical,
+						criticalSince: &t9,
+						mutex:         &sync.RWMutex{},
 					},
 				}
 
-				hc.timeOfFirstCriticalError = t9
-
 				status := hc.getCheckStatus(check)
 				So(status, ShouldEqual, StatusWarning)
-				So(hc.timeOfFirstCriticalError, ShouldEqual, t9)
-				So(check.state.LastSuccess(), ShouldBeNil)
+				So(check.state.CriticalSince(), ShouldResemble, &t9)
 			})
 		})
 
-		Convey("When time of first critical state is less than the critical timeout"+
-			"but the check has a last successful state that occurred within the critical timeout", func() {
+		Convey("When the time at which the check became critical is unknown", func() {
 			Convey("Then the returning status is warning", func() {
 				check := &Check{
 					state: &CheckState{
 						status:      StatusCritical,
-						lastSuccess: &t9,
+						lastSuccess: &t20,
 						mutex:       &sync.RWMutex{},
 					},
 				}
 
-				hc.timeOfFirstCriticalError = t10
-
 				status := hc.getCheckStatus(check)
 				So(status, ShouldEqual, StatusWarning)
-				So(hc.timeOfFirstCriticalError, ShouldHappenOnOrBetween, t0, time.Now().UTC())
-				So(check.state.LastSuccess(), ShouldResemble, &t9)
+				So(check.state.CriticalSince(), ShouldBeNil)
+			})
+		})
+
+		Convey("When the check has its own critical timeout", func() {
+			check := &Check{
+				state: &CheckState{
+					status:        StatusCritical,
+					criticalSince: &t9,
+					mutex:         &sync.RWMutex{},
+				},
+			}
+
+			Convey("Then the returning status is critical if it has been critical for longer than its own timeout", func() {
+				WithCriticalTimeout(30 * time.Second)(check)
+				So(hc.getCheckStatus(check), ShouldEqual, StatusCritical)
+			})
+
+			Convey("Then the returning status is warning if it has been critical for less than its own timeout", func() {
+				WithCriticalTimeout(time.Hour)(check)
+				check.state.criticalSince = &t20
+				So(hc.getCheckStatus(check), ShouldEqual, StatusWarning)
+			})
+		})
+
+		Convey("When two checks became critical at different times", func() {
+			oldCheck := &Check{
+				state: &CheckState{
+					status:        StatusCritical,
+					criticalSince: &t20,
+					mutex:         &sync.RWMutex{},
+				},
+			}
+			newCheck := &Check{
+				state: &CheckState{
+					status:        StatusCritical,
+					criticalSince: &t9,
+					mutex:         &sync.RWMutex{},
+				},
+			}
+
+			Convey("Then the check that became critical later does not inherit the critical time of the other one", func() {
+				So(hc.getCheckStatus(oldCheck), ShouldEqual, StatusCritical)
+				So(hc.getCheckStatus(newCheck), ShouldEqual, StatusWarning)
 			})
 		})
 	})
@@ -284,13 +316,25 @@ func TestIsAppHealthy(t *testing.T) {
 	}
 
 	criticalCheck := CheckState{
-		name:        "service-3",
-		status:      StatusCritical,
-		statusCode:  http.StatusInternalServerError,
-		message:     "Service is unavailable",
-		lastChecked: &t1,
-		lastSuccess: &t20,
-		lastFailure: &t1,
+		name:          "service-3",
+		status:        StatusCritical,
+		statusCode:    http.StatusInternalServerError,
+		message:       "Service is unavailable",
+		lastChecked:   &t1,
+		lastSuccess:   &t20,
+		lastFailure:   &t1,
+		criticalSince: &t10,
+	}
+
+	recentCriticalCheck := CheckState{
+		name:          "service-4",
+		status:        StatusCritical,
+		statusCode:    http.StatusInternalServerError,
+		message:       "Service has just become unavailable",
+		lastChecked:   &t1,
+		lastSuccess:   &t10,
+		lastFailure:   &t1,
+		criticalSince: &t1,
 	}
 
 	Convey("Given healthcheck contains two checks, both with statuses of OK", t, func() {
@@ -330,10 +374,9 @@ func TestIsAppHealthy(t *testing.T) {
 
 			// Create health check object
 			hc := getTestHealthCheck(t20, criticalErrTimeout)
-			hc.timeOfFirstCriticalError = t1
 
 			// Adding two healthy checks
-			statuses := []CheckState{healthyCheck, criticalCheck}
+			statuses := []CheckState{healthyCheck, recentCriticalCheck}
 			hc.Checks = createChecksSlice(statuses, true)
 
 			status := hc.isAppHealthy()
@@ -347,7 +390,6 @@ func TestIsAppHealthy(t *testing.T) {
 
 			// Create health check object
 			hc := getTestHealthCheck(t20, criticalErrTimeout)
-			hc.timeOfFirstCriticalError = t10
 
 			// Adding two healthy checks
 			statuses := []CheckState{healthyCheck, criticalCheck}
@@ -364,10 +406,9 @@ func TestIsAppHealthy(t *testing.T) {
 
 			// Create health check object
 			hc := getTestHealthCheck(t20, criticalErrTimeout)
-			hc.timeOfFirstCriticalError = t1
 
 			// Adding two healthy checks
-			statuses := []CheckState{warningCheck, criticalCheck}
+			statuses := []CheckState{warningCheck, recentCriticalCheck}
 			hc.Checks = createChecksSlice(statuses, true)
 
 			status := hc.isAppHealthy()
@@ -375,13 +416,27 @@ func TestIsAppHealthy(t *testing.T) {
 		})
 	})
 
+	Convey("Given healthcheck contains two CRITICAL checks, one that has succeeded the critical timeout "+
+		"and one that has not", t, func() {
+		Convey("Then the returning status is CRITICAL", func() {
+
+			// Create health check object
+			hc := getTestHealthCheck(t20, criticalErrTimeout)
+
+			statuses := []CheckState{recentCriticalCheck, criticalCheck}
+			hc.Checks = createChecksSlice(statuses, true)
+
+			status := hc.isAppHealthy()
+			So(status, ShouldEqual, StatusCritical)
+		})
+	})
+
 	Convey("Given healthcheck contains two checks, one with status of WARNING"+
 		"and the other with status CRITICAL and has succeeded the critical timeout", t, func() {
 		Convey("Then the returning status is CRITICAL", func() {
 
 			// Create health check object
 			hc := getTestHealthCheck(t20, criticalErrTimeout)
-			hc.timeOfFirstCriticalError = t10
 
 			// Adding two healthy checks
 			statuses := []CheckState{warningCheck, criticalCheck}
@@ -393,6 +448,110 @@ func TestIsAppHealthy(t *testing.T) {
 	})
 }
 
+// Testing that getCheckStatus() and the aggregated statuses respect the check criticality
+func TestCheckCriticality(t *testing.T) {
+	criticalErrTimeout := 10 * time.Minute
+
+	t0 := time.Now().UTC()
+	t1 := t0.Add(-1 * time.Minute)   // 1 min ago
+	t20 := t0.Add(-20 * time.Minute) // 20 min ago
+	t30 := t0.Add(-30 * time.Minute) // 30 min ago
+
+	healthyCheck := CheckState{
+		name:        "service-1",
+		status:      StatusOK,
+		lastChecked: &t1,
+		lastSuccess: &t1,
+	}
+
+	warningCheck := CheckState{
+		name:        "service-2",
+		status:      StatusWarning,
+		lastChecked: &t1,
+		lastFailure: &t1,
+	}
+
+	criticalCheck := CheckState{
+		name:          "service-3",
+		status:        StatusCritical,
+		lastChecked:   &t1,
+		lastSuccess:   &t30,
+		lastFailure:   &t1,
+		criticalSince: &t20,
+	}
+
+	Convey("Given a degrading check", t, func() {
+		hc := getTestHealthCheck(t20, criticalErrTimeout)
+
+		Convey("Then an OK state results in an OK status", func() {
+			check := createATestCheck(healthyCheck, true)
+			check.criticality = CriticalityDegrading
+			So(hc.getCheckStatus(check), ShouldEqual, StatusOK)
+		})
+
+		Convey("Then a WARNING state results in a WARNING status", func() {
+			check := createATestCheck(warningCheck, true)
+			check.criticality = CriticalityDegrading
+			So(hc.getCheckStatus(check), ShouldEqual, StatusWarning)
+		})
+
+		Convey("Then a CRITICAL state beyond the critical timeout results in a WARNING status", func() {
+			check := createATestCheck(criticalCheck, true)
+			check.criticality = CriticalityDegrading
+			So(hc.getCheckStatus(check), ShouldEqual, StatusWarning)
+		})
+	})
+
+	Convey("Given an informational check", t, func() {
+		hc := getTestHealthCheck(t20, criticalErrTimeout)
+
+		Convey("Then any state results in an OK status", func() {
+			for _, state := range []CheckState{healthyCheck, warningCheck, criticalCheck} {
+				check := createATestCheck(state, true)
+				check.criticality = CriticalityInformational
+				So(hc.getCheckStatus(check), ShouldEqual, StatusOK)
+			}
+		})
+
+		Convey("Then the app is not considered to be starting up if the informational check has not run yet", func() {
+			check := createATestCheck(CheckState{}, false)
+			check.criticality = CriticalityInformational
+			hc.Checks = []*Check{createATestCheck(healthyCheck, true), check}
+			So(hc.isAppStartingUp(), ShouldBeFalse)
+			So(hc.getAppStatus(context.Background()), ShouldEqual, StatusOK)
+		})
+	})
+
+	Convey("Given a healthcheck with a critical check and an advisory check that have both been CRITICAL beyond the timeout", t, func() {
+		hc := getTestHealthCheck(t20, criticalErrTimeout)
+
+		critical := createATestCheck(criticalCheck, true)
+		degrading := createATestCheck(criticalCheck, true)
+		degrading.criticality = CriticalityDegrading
+		informational := createATestCheck(criticalCheck, true)
+		informational.criticality = CriticalityInformational
+
+		Convey("Then the app is CRITICAL when the critical check is included", func() {
+			hc.Checks = []*Check{critical, degrading, informational}
+			So(hc.isAppHealthy(), ShouldEqual, StatusCritical)
+		})
+
+		Convey("Then the status of a degrading and an informational check together is WARNING", func() {
+			So(hc.getChecksStatus([]*Check{degrading, informational}), ShouldEqual, StatusWarning)
+		})
+
+		Convey("Then the status of only an informational check is OK", func() {
+			So(hc.getChecksStatus([]*Check{informational}), ShouldEqual, StatusOK)
+		})
+
+		Convey("Then the advisory checks are reported in the handler response with their own status", func() {
+			statuses := []CheckState{criticalCheck}
+			hc.Checks = []*Check{informational}
+			runHealthHandlerAndTest(t, &hc, StatusOK, testVersion, t20, statuses, http.StatusOK)
+		})
+	})
+}
+
 func TestHandlerSingleCheck(t *testing.T) {
 	t0 := time.Now().UTC()
 	t10 := t0.Add(-10 * time.Minute) // 10 min ago
@@ -419,22 +578,24 @@ func TestHandlerSingleCheck(t *testing.T) {
 		lastFailure: &t0,
 	}
 	freshCriticalStatus := CheckState{
-		name:        "Some App 3",
-		status:      StatusCritical,
-		statusCode:  http.StatusInternalServerError,
-		message:     "Something has been critical for the past 10 minutes",
-		lastChecked: &t0,
-		lastSuccess: &t10,
-		lastFailure: &t0,
+		name:          "Some App 3",
+		status:        StatusCritical,
+		statusCode:    http.StatusInternalServerError,
+		message:       "Something has been critical for the past 10 minutes",
+		lastChecked:   &t0,
+		lastSuccess:   &t20,
+		lastFailure:   &t0,
+		criticalSince: &t10,
 	}
 	oldCriticalStatus := CheckState{
-		name:        "Some App 4",
-		status:      StatusCritical,
-		statusCode:  http.StatusInternalServerError,
-		message:     "Something has been critical for the past 30 minutes",
-		lastChecked: &t0,
-		lastSuccess: &t30,
-		lastFailure: &t0,
+		name:          "Some App 4",
+		status:        StatusCritical,
+		statusCode:    http.StatusInternalServerError,
+		message:       "Something has been critical for the past 20 minutes",
+		lastChecked:   &t0,
+		lastSuccess:   &t30,
+		lastFailure:   &t0,
+		criticalSince: &t20,
 	}
 
 	nilStatus := CheckState{
@@ -457,15 +618,16 @@ func TestHandlerSingleCheck(t *testing.T) {
 		lastFailure: &t0,
 	}
 	criticalNeverHealthyStatus := CheckState{
-		name:        "Some App 8",
-		status:      StatusCritical,
-		statusCode:  http.StatusTooManyRequests,
-		message:     "Something is critical",
-		lastChecked: &t0,
-		lastFailure: &t0,
+		name:          "Some App 8",
+		status:        StatusCritical,
+		statusCode:    http.StatusTooManyRequests,
+		message:       "Something is critical",
+		lastChecked:   &t0,
+		lastFailure:   &t0,
+		criticalSince: &t0,
 	}
 
-	Convey("Given a healthcheck with no past failures or successes", t, func() {
+	Convey("Given a healthcheck with a single check", t, func() {
 
 		hc := getTestHealthCheck(t0, criticalErrTimeout)
 
@@ -473,110 +635,54 @@ func TestHandlerSingleCheck(t *testing.T) {
 			statuses := []CheckState{nilStatus}
 			hc.Checks = createChecksSlice(statuses, true)
 			runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, t0, statuses, http.StatusTooManyRequests)
-			// timeOfFirstCriticalError not set
-			So(hc.timeOfFirstCriticalError, ShouldResemble, time.Time{})
 		})
 
 		Convey("Then a healthy check that has never been unhealthy should result in the app reporting back as healthy", func() {
 			statuses := []CheckState{healthyNeverUnhealthyStatus}
 			hc.Checks = createChecksSlice(statuses, true)
 			runHealthHandlerAndTest(t, &hc, StatusOK, testVersion, t0, statuses, http.StatusOK)
-			// timeOfFirstCriticalError not set
-			So(hc.timeOfFirstCriticalError, ShouldResemble, time.Time{})
 		})
 		Convey("Then a healthy check that has been unhealthy in the past should result in the app reporting back as healthy", func() {
 			statuses := []CheckState{healthyStatus1}
 			hc.Checks = createChecksSlice(statuses, true)
 			runHealthHandlerAndTest(t, &hc, StatusOK, testVersion, t0, statuses, http.StatusOK)
-			// timeOfFirstCriticalError not set
-			So(hc.timeOfFirstCriticalError, ShouldResemble, time.Time{})
 		})
 		Convey("Then an unhealthy check that has never been healthy should result in the app reporting back as warning", func() {
 			statuses := []CheckState{unhealthyNeverHealthyStatus}
 			hc.Checks = createChecksSlice(statuses, true)
 			runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, t0, statuses, http.StatusTooManyRequests)
-			// timeOfFirstCriticalError not set
-			So(hc.timeOfFirstCriticalError, ShouldResemble, time.Time{})
 		})
 		Convey("Then an unhealthy check that has been healthy in the past should result in the app reporting back as warning", func() {
 			statuses := []CheckState{unhealthyStatus}
 			hc.Checks = createChecksSlice(statuses, true)
 			runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, t0, statuses, http.StatusTooManyRequests)
-			// timeOfFirstCriticalError not set
-			So(hc.timeOfFirstCriticalError, ShouldResemble, time.Time{})
 		})
-		Convey("Then a critical check that has never been healthy should result in the app reporting back as warning and updating timestamp for first critical error", func() {
+		Convey("Then a critical check that has never been healthy and has just become critical should result in the app reporting back as warning", func() {
 			statuses := []CheckState{criticalNeverHealthyStatus}
 			hc.Checks = createChecksSlice(statuses, true)
 			runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, t0, statuses, http.StatusTooManyRequests)
-			// timeOfFirstCriticalError set to this check's failure time
-			So(hc.timeOfFirstCriticalError, ShouldHappenWithin, time.Second, t0)
 		})
-		Convey("Then a critical check that has been healthy in the past should result in the app reporting back as warning and updating timestamp for first critical error", func() {
-			statuses := []CheckState{oldCriticalStatus}
-			hc.Checks = createChecksSlice(statuses, true)
-			runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, t0, statuses, http.StatusTooManyRequests)
-			// timeOfFirstCriticalError set to this check's failure time
-			So(hc.timeOfFirstCriticalError, ShouldHappenWithin, time.Second, t0)
-		})
-	})
-
-	Convey("Given a healthcheck with a recent past critical check (timeout not expired), and no success received since", t, func() {
-
-		hc := getTestHealthCheck(t0, criticalErrTimeout)
-		hc.timeOfFirstCriticalError = t10
-
-		Convey("Then a healthy check should result in the app reporting back as healthy", func() {
-			statuses := []CheckState{healthyNeverUnhealthyStatus}
-			hc.Checks = createChecksSlice(statuses, true)
-			runHealthHandlerAndTest(t, &hc, StatusOK, testVersion, t0, statuses, http.StatusOK)
-			// timeOfFirstCriticalError not updated
-			So(hc.timeOfFirstCriticalError, ShouldResemble, t10)
-		})
-		Convey("Then a recent critical check happening before the timeout expires should result in the app reporting back as warning", func() {
+		Convey("Then a critical check that has been critical for less than the timeout should result in the app reporting back as warning", func() {
 			statuses := []CheckState{freshCriticalStatus}
 			hc.Checks = createChecksSlice(statuses, true)
 			runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, t0, statuses, http.StatusTooManyRequests)
-			// timeOfFirstCriticalError not updated
-			So(hc.timeOfFirstCriticalError, ShouldResemble, t10)
 		})
-		Convey("Then a critical check that has been critical for longer than the timeout and the value of first critical error, "+
-			"should result in the app reporting back as warning and not updating timestamp for first critical error", func() {
+		Convey("Then a critical check that has been critical for longer than the timeout should result in the app reporting back as critical", func() {
 			statuses := []CheckState{oldCriticalStatus}
 			hc.Checks = createChecksSlice(statuses, true)
-			runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, t0, statuses, http.StatusTooManyRequests)
-			// timeOfFirstCriticalError not updated
-			So(hc.timeOfFirstCriticalError, ShouldResemble, t10)
-		})
-	})
-
-	Convey("Given a healthcheck with an old past critical check (timeout expired), and no success received since", t, func() {
-
-		hc := getTestHealthCheck(t0, criticalErrTimeout)
-		hc.timeOfFirstCriticalError = t20
-
-		Convey("Then a healthy check should result in the app reporting back as healthy", func() {
-			statuses := []CheckState{healthyNeverUnhealthyStatus}
-			hc.Checks = createChecksSlice(statuses, true)
-			runHealthHandlerAndTest(t, &hc, StatusOK, testVersion, t0, statuses, http.StatusOK)
-			// timeOfFirstCriticalError not set
-			So(hc.timeOfFirstCriticalError, ShouldResemble, t20)
+			runHealthHandlerAndTest(t, &hc, StatusCritical, testVersion, t0, statuses, http.StatusInternalServerError)
 		})
-		Convey("Then a recent critical check (last success more recent than first critical) should result in the app reporting back as warning "+
-			"and refresh timestamp for first critical error", func() {
+		Convey("Then a critical check that has been critical for longer than its own, shorter, timeout should result in the app reporting back as critical", func() {
 			statuses := []CheckState{freshCriticalStatus}
 			hc.Checks = createChecksSlice(statuses, true)
-			runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, t0, statuses, http.StatusTooManyRequests)
-			// timeOfFirstCriticalError set to this check's failure time
-			So(hc.timeOfFirstCriticalError, ShouldHappenWithin, time.Second, t0)
+			WithCriticalTimeout(5 * time.Minute)(hc.Checks[0])
+			runHealthHandlerAndTest(t, &hc, StatusCritical, testVersion, t0, statuses, http.StatusInternalServerError)
 		})
-		Convey("Then a critical check (last success older than first critical) should result in the app reporting back as critical "+
-			"and not refreshing timestamp for first critical error", func() {
+		Convey("Then a critical check that has been critical for less than its own, longer, timeout should result in the app reporting back as warning", func() {
 			statuses := []CheckState{oldCriticalStatus}
 			hc.Checks = createChecksSlice(statuses, true)
-			runHealthHandlerAndTest(t, &hc, StatusCritical, testVersion, t0, statuses, http.StatusInternalServerError)
-			// timeOfFirstCriticalError set to this check's failure time
-			So(hc.timeOfFirstCriticalError, ShouldResemble, t20)
+			WithCriticalTimeout(time.Hour)(hc.Checks[0])
+			runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, t0, statuses, http.StatusTooManyRequests)
 		})
 	})
 
@@ -585,6 +691,7 @@ func TestHandlerSingleCheck(t *testing.T) {
 func TestHandlerMultipleChecks(t *testing.T) {
 	testStartTime := time.Now().UTC().Add(-20 * time.Minute)
 	priorTestTime := testStartTime.Add(-30 * time.Minute)
+	justFailedTime := time.Now().UTC()
 	healthyStatus1 := CheckState{
 		name:        "Some App 1",
 		status:      StatusOK,
@@ -621,73 +728,77 @@ func TestHandlerMultipleChecks(t *testing.T) {
 		lastFailure: &testStartTime,
 	}
 	criticalStatus := CheckState{
-		name:        "Some App 5",
-		status:      StatusCritical,
-		statusCode:  http.StatusInternalServerError,
-		message:     "Something has been critical for the past 30 minutes",
-		lastChecked: &testStartTime,
-		lastSuccess: &priorTestTime,
-		lastFailure: &testStartTime,
+		name:          "Some App 5",
+		status:        StatusCritical,
+		statusCode:    http.StatusInternalServerError,
+		message:       "Something has been critical for the past 30 minutes",
+		lastChecked:   &testStartTime,
+		lastSuccess:   &priorTestTime,
+		lastFailure:   &testStartTime,
+		criticalSince: &priorTestTime,
 	}
 	freshCriticalStatus := CheckState{
-		name:        "Some App 6",
-		status:      StatusCritical,
-		statusCode:  http.StatusInternalServerError,
-		message:     "Something has been critical for the past 30 minutes",
-		lastChecked: &testStartTime,
-		lastSuccess: &testStartTime,
-		lastFailure: &priorTestTime,
+		name:          "Some App 6",
+		status:        StatusCritical,
+		statusCode:    http.StatusInternalServerError,
+		message:       "Something has just become critical",
+		lastChecked:   &justFailedTime,
+		lastSuccess:   &testStartTime,
+		lastFailure:   &justFailedTime,
+		criticalSince: &justFailedTime,
 	}
 
 	Convey("Given a complete Healthy set of checks the app should report back as healthy", t, func() {
 		statuses := []CheckState{healthyStatus1, healthyStatus2, healthyStatus3}
 		hc := createHealthCheck(statuses, testStartTime, 10*time.Minute, true)
-		hc.timeOfFirstCriticalError = testStartTime.Add(-30 * time.Minute)
 		runHealthHandlerAndTest(t, &hc, StatusOK, testVersion, testStartTime, statuses, http.StatusOK)
 	})
 	Convey("Given a healthy app and an unhealthy app", t, func() {
 		statuses := []CheckState{healthyStatus1, unhealthyStatus}
 		hc := createHealthCheck(statuses, testStartTime, 15*time.Second, true)
-		hc.timeOfFirstCriticalError = testStartTime.Add(-30 * time.Minute)
 		runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, testStartTime, statuses, http.StatusTooManyRequests)
 	})
 	Convey("Given a healthy app and a critical app that is beyond the threshold", t, func() {
 		checks := []CheckState{healthyStatus1, criticalStatus}
 		hc := createHealthCheck(checks, testStartTime, 10*time.Minute, true)
-		hc.timeOfFirstCriticalError = testStartTime.Add(-22 * time.Minute)
 		runHealthHandlerAndTest(t, &hc, StatusCritical, testVersion, testStartTime, checks, http.StatusInternalServerError)
 	})
 	Convey("Given an unhealthy app and an app that has just turned critical and is under the critical threshold", t, func() {
 		statuses := []CheckState{unhealthyStatus, freshCriticalStatus}
 		hc := createHealthCheck(statuses, testStartTime, 10*time.Minute, true)
-		hc.timeOfFirstCriticalError = testStartTime.Add(-1 * time.Minute)
 		runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, testStartTime, statuses, http.StatusTooManyRequests)
 	})
 	Convey("Given an unhealthy app and an app that has been critical for longer than the critical threshold", t, func() {
 		statuses := []CheckState{unhealthyStatus, criticalStatus}
 		hc := createHealthCheck(statuses, testStartTime, 10*time.Minute, true)
-		hc.timeOfFirstCriticalError = testStartTime.Add(-22 * time.Minute)
 		runHealthHandlerAndTest(t, &hc, StatusCritical, testVersion, testStartTime, statuses, http.StatusInternalServerError)
 	})
+	Convey("Given an app that has been critical for longer than the critical threshold and an app that has just turned critical", t, func() {
+		statuses := []CheckState{criticalStatus, freshCriticalStatus}
+		hc := createHealthCheck(statuses, testStartTime, 10*time.Minute, true)
+		runHealthHandlerAndTest(t, &hc, StatusCritical, testVersion, testStartTime, statuses, http.StatusInternalServerError)
+
+		Convey("Then the app that has just turned critical is still considered to be under the critical threshold", func() {
+			So(hc.getCheckStatus(hc.Checks[0]), ShouldEqual, StatusCritical)
+			So(hc.getCheckStatus(hc.Checks[1]), ShouldEqual, StatusWarning)
+		})
+	})
 	Convey("Given an app just started up", t, func() {
 		statuses := []CheckState{freshCriticalStatus}
 		justStartedTime := time.Now().UTC()
 		hc := createHealthCheck(statuses, justStartedTime, 10*time.Minute, false)
-		hc.timeOfFirstCriticalError = justStartedTime
 		runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, justStartedTime, nil, http.StatusTooManyRequests)
 	})
 	Convey("Given an app has begun to start but not finished starting up completely", t, func() {
 		statuses := []CheckState{freshCriticalStatus}
 		justStartedTime := time.Now().UTC()
 		hc := createHealthCheck(statuses, justStartedTime, 10*time.Minute, true)
-		hc.timeOfFirstCriticalError = justStartedTime
 		runHealthHandlerAndTest(t, &hc, StatusWarning, testVersion, justStartedTime, statuses, http.StatusTooManyRequests)
 	})
 	Convey("Given no apps", t, func() {
 		var checks []*Check
 		var statuses []CheckState
 		hc := getTestHealthCheck(testStartTime, 10*time.Minute)
-		hc.timeOfFirstCriticalError = testStartTime.Add(-30 * time.Minute)
 		hc.Checks = checks
 
 		runHealthHandlerAndTest(t, &hc, StatusOK, testVersion, testStartTime, statuses, http.StatusOK)
@@ -702,6 +813,7 @@ func createATestCheck(stateToReturn CheckState, hasPreviousCheck bool) *Check {
 		state.lastChecked = stateToReturn.lastChecked
 		state.lastSuccess = stateToReturn.lastSuccess
 		state.lastFailure = stateToReturn.lastFailure
+		state.criticalSince = stateToReturn.criticalSince
 		return nil
 	}
 	check, _ := NewCheck(stateToReturn.name, checkerFunc)
@@ -712,6 +824,7 @@ func createATestCheck(stateToReturn CheckState, hasPreviousCheck bool) *Check {
 		check.state.lastChecked = stateToReturn.lastChecked
 		check.state.lastSuccess = stateToReturn.lastSuccess
 		check.state.lastFailure = stateToReturn.lastFailure
+		check.state.criticalSince = stateToReturn.criticalSince
 	}
 	return check
 }
@@ -763,7 +876,10 @@ func runHealthHandlerAndTest(t *testing.T, hc *HealthCheck, desiredStatus string
 			s := *check.state
 			check.state.mutex.RUnlock()
 			s.mutex = nil
-			So(s, ShouldResemble, statuses[i])
+			// the time of the first critical error is not part of the response
+			expected := statuses[i]
+			expected.criticalSince = nil
+			So(s, ShouldResemble, expected)
 		}
 	}
 }
@@ -3,33 +3,55 @@ package healthcheck
 import (
 	"context"
 	"errors"
+	"fmt"
 	"runtime"
 	"strconv"
 	"sync"
 	"time"
 
 	"github.com/ONSdigital/log.go/v2/log"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const language = "go"
 
 // HealthCheck represents the app's health check, including its component checks
 type HealthCheck struct {
-	Status                   string        `json:"status"`
-	Version                  VersionInfo   `json:"version"`
-	Uptime                   time.Duration `json:"uptime"`
-	StartTime                time.Time     `json:"start_time"`
-	Checks                   []*Check      `json:"checks"`
-	interval                 time.Duration
-	criticalErrorTimeout     time.Duration
-	timeOfFirstCriticalError time.Time
-	tickers                  []*ticker
-	context                  context.Context
-	tickersWaitgroup         *sync.WaitGroup
-	statusLock               *sync.RWMutex
-	subscribers              map[Subscriber]map[*Check]struct{}
-	subsMutex                *sync.Mutex
-	stopper                  chan struct{}
+	Status                 string                   `json:"status"`
+	Version                VersionInfo              `json:"version"`
+	Uptime                 time.Duration            `json:"uptime"`
+	StartTime              time.Time                `json:"start_time"`
+	Checks                 []*Check                 `json:"checks"`
+	Dependencies           map[string]*Dependency   `json:"dependencies,omitempty"`
+	Groups                 map[string]*GroupSummary `json:"groups,omitempty"`
+	Override               *Override                `json:"override,omitempty"`
+	Draining               bool                     `json:"draining,omitempty"`
+	interval               time.Duration
+	criticalErrorTimeout   time.Duration
+	tickers                []*ticker
+	context                context.Context
+	tickersWaitgroup       *sync.WaitGroup
+	statusLock             *sync.RWMutex
+	subscribers            map[Subscriber]map[*Check]struct{}
+	subsMutex              *sync.Mutex
+	watches                map[*watch]struct{}
+	droppedWatchEvents     uint64
+	dispatchers            map[Subscriber]*dispatcher
+	removedCounters        notificationCounters
+	stuckSubscriberTimeout time.Duration
+	stopper                chan struct{}
+	lifecycle              lifecycle
+	lifecycleMutex         *sync.Mutex
+	tracerProvider         trace.TracerProvider
+	meterProvider          metric.MeterProvider
+	telemetry              *telemetry
+	responseFormat         ResponseFormat
+	statusCodes            map[string]statusCodeMapping
+	responseCache          *responseCache
+	escalationTimer        Timer
+	aggregationPolicy      AggregationPolicy
+	clock                  Clock
 }
 
 // VersionInfo represents the version information of an app
@@ -41,12 +63,32 @@ type VersionInfo struct {
 	Version         string    `json:"version"`
 }
 
+// Option configures a HealthCheck
+type Option func(*HealthCheck)
+
+// WithTracerProvider sets the OpenTelemetry tracer provider used to create a span for every checker run.
+// Defaults to the global tracer provider.
+func WithTracerProvider(tracerProvider trace.TracerProvider) Option {
+	return func(hc *HealthCheck) {
+		hc.tracerProvider = tracerProvider
+	}
+}
+
+// WithMeterProvider sets the OpenTelemetry meter provider used to record the duration and outcome of every checker run.
+// Defaults to the global meter provider.
+func WithMeterProvider(meterProvider metric.MeterProvider) Option {
+	return func(hc *HealthCheck) {
+		hc.meterProvider = meterProvider
+	}
+}
+
 // New returns a new instantiated HealthCheck object. Caller to provide:
 // version information of the app,
 // criticalTimeout for how long to wait until an unhealthy dependent propagates its state to make this app unhealthy
 // interval in which to check health of dependencies
-func New(version VersionInfo, criticalTimeout, interval time.Duration) HealthCheck {
-	return HealthCheck{
+// any options to configure the health check
+func New(version VersionInfo, criticalTimeout, interval time.Duration, opts ...Option) HealthCheck {
+	hc := HealthCheck{
 		Checks:               []*Check{},
 		Version:              version,
 		criticalErrorTimeout: criticalTimeout,
@@ -54,9 +96,16 @@ func New(version VersionInfo, criticalTimeout, interval time.Duration) HealthChe
 		tickers:              []*ticker{},
 		tickersWaitgroup:     &sync.WaitGroup{},
 		statusLock:           &sync.RWMutex{},
+		lifecycleMutex:       &sync.Mutex{},
 		subscribers:          map[Subscriber]map[*Check]struct{}{},
 		subsMutex:            &sync.Mutex{},
 	}
+	for _, opt := range opts {
+		opt(&hc)
+	}
+	hc.telemetry = newTelemetry(hc.tracerProvider, hc.meterProvider)
+
+	return hc
 }
 
 // NewVersionInfo returns a health check version info object. Caller to provide:
@@ -81,44 +130,243 @@ func NewVersionInfo(buildTime, gitCommit, version string) (VersionInfo, error) {
 }
 
 // AddCheck adds a provided checker to the health check
-func (hc *HealthCheck) AddCheck(name string, checker Checker) (err error) {
-	_, err = hc.AddAndGetCheck(name, checker)
+func (hc *HealthCheck) AddCheck(name string, checker Checker, opts ...CheckOption) (err error) {
+	_, err = hc.AddAndGetCheck(name, checker, opts...)
 	return err
 }
 
 // AddAndGetCheck adds a provided checker to the health check
-// and returns the corresponding Check pointer, which maybe used for subscription
-func (hc *HealthCheck) AddAndGetCheck(name string, checker Checker) (check *Check, err error) {
-	check, err = NewCheck(name, checker)
+// and returns the corresponding Check pointer, which maybe used for subscription.
+// Check options may be provided to override the interval, timeout or initial delay for this check.
+func (hc *HealthCheck) AddAndGetCheck(name string, checker Checker, opts ...CheckOption) (check *Check, err error) {
+	check, err = NewCheck(name, checker, opts...)
 	if err != nil {
 		return nil, err
 	}
-	check.state.changeCallback = hc.healthChangeCallback
+	check.state.changeCallback = hc.checkChangeCallback(check)
+	check.state.clock = hc.clock
 
+	hc.statusLock.Lock()
 	hc.Checks = append(hc.Checks, check)
-
-	ticker := createTicker(hc.interval, check)
+	ticker := hc.createCheckTicker(check)
 	hc.tickers = append(hc.tickers, ticker)
+	ctx := hc.context
+	running := hc.isRunning()
+	hc.statusLock.Unlock()
+
+	if running {
+		ticker.start(ctx, hc.tickersWaitgroup)
+	}
+
+	return check, nil
+}
+
+// RemoveCheck removes the provided check from the health check, stopping its ticker and removing it from every subscription.
+// The app status is updated straight away, and the subscribers are notified of their new state.
+func (hc *HealthCheck) RemoveCheck(check *Check) error {
+	removed := hc.removeChecks(func(c *Check) bool {
+		return c == check
+	})
+	if len(removed) == 0 {
+		return errors.New("check not found")
+	}
+
+	hc.healthChangeCallback()
+	return nil
+}
 
-	if hc.context != nil {
-		ticker.start(hc.context, hc.tickersWaitgroup)
+// RemoveCheckByName removes all the checks with the provided name from the health check,
+// stopping their tickers and removing them from every subscription.
+// The app status is updated straight away, and the subscribers are notified of their new state.
+func (hc *HealthCheck) RemoveCheckByName(name string) error {
+	removed := hc.removeChecks(func(c *Check) bool {
+		return c.state.Name() == name
+	})
+	if len(removed) == 0 {
+		return fmt.Errorf("check not found: %s", name)
+	}
+
+	hc.healthChangeCallback()
+	return nil
+}
+
+// ReplaceCheck replaces the first check with the provided name by a new check with the provided checker and check options,
+// and returns the new Check pointer. The new check takes the place of the old one in the health check and in every subscription.
+// The ticker of the old check is stopped and, if the health check has been started, a ticker is started for the new check.
+func (hc *HealthCheck) ReplaceCheck(name string, checker Checker, opts ...CheckOption) (*Check, error) {
+	check, err := NewCheck(name, checker, opts...)
+	if err != nil {
+		return nil, err
+	}
+	check.state.changeCallback = hc.checkChangeCallback(check)
+	check.state.clock = hc.clock
+
+	hc.statusLock.Lock()
+	var old *Check
+	for i, c := range hc.Checks {
+		if c.state.Name() == name {
+			old = c
+			hc.Checks[i] = check
+			break
+		}
+	}
+	if old == nil {
+		hc.statusLock.Unlock()
+		return nil, fmt.Errorf("check not found: %s", name)
+	}
+
+	var oldTicker *ticker
+	ticker := hc.createCheckTicker(check)
+	for i, t := range hc.tickers {
+		if t.check == old {
+			oldTicker = t
+			hc.tickers[i] = ticker
+			break
+		}
+	}
+	ctx := hc.context
+	running := hc.isRunning()
+	hc.statusLock.Unlock()
+
+	old.state.setChangeCallback(nil)
+	if running {
+		if oldTicker != nil {
+			oldTicker.stop()
+		}
+		ticker.start(ctx, hc.tickersWaitgroup)
+	} else if oldTicker != nil {
+		// the ticker has not been started, but its time ticker has been created with it and must be stopped
+		oldTicker.close()
+	}
+
+	hc.subsMutex.Lock()
+	for _, checks := range hc.subscribers {
+		if _, ok := checks[old]; ok {
+			delete(checks, old)
+			checks[check] = struct{}{}
+		}
 	}
+	hc.replaceInWatches(old, check)
+	hc.subsMutex.Unlock()
 
+	hc.healthChangeCallback()
 	return check, nil
 }
 
+// removeChecks removes the checks that match from the health check, stopping their tickers
+// and removing them from every subscription. It returns the removed checks.
+func (hc *HealthCheck) removeChecks(match func(*Check) bool) []*Check {
+	hc.statusLock.Lock()
+	removed := []*Check{}
+	checks := make([]*Check, 0, len(hc.Checks))
+	for _, check := range hc.Checks {
+		if match(check) {
+			removed = append(removed, check)
+			continue
+		}
+		checks = append(checks, check)
+	}
+	hc.Checks = checks
+
+	stopping := []*ticker{}
+	tickers := make([]*ticker, 0, len(hc.tickers))
+	for _, ticker := range hc.tickers {
+		if match(ticker.check) {
+			stopping = append(stopping, ticker)
+			continue
+		}
+		tickers = append(tickers, ticker)
+	}
+	hc.tickers = tickers
+	started := hc.isRunning()
+	hc.statusLock.Unlock()
+
+	// the tickers are stopped without holding the status lock, as a running check may need it to report a state change
+	for _, check := range removed {
+		check.state.setChangeCallback(nil)
+	}
+	for _, ticker := range stopping {
+		if started {
+			ticker.stop()
+		} else {
+			// the ticker has not been started, but its time ticker has been created with it and must be stopped
+			ticker.close()
+		}
+	}
+
+	hc.subsMutex.Lock()
+	defer hc.subsMutex.Unlock()
+	for s, checks := range hc.subscribers {
+		for _, check := range removed {
+			delete(checks, check)
+		}
+		// a subscriber that is left without checks is removed, the same as when it unsubscribes from them
+		if len(checks) == 0 {
+			hc.removeSubscriber(s)
+		}
+	}
+	hc.removeFromWatches(removed)
+
+	return removed
+}
+
+// createCheckTicker creates a ticker for the provided check, using the check interval if it has one.
+// The caller must hold the status lock.
+func (hc *HealthCheck) createCheckTicker(check *Check) *ticker {
+	interval := hc.interval
+	if check.interval > 0 {
+		interval = check.interval
+	}
+
+	if hc.telemetry == nil {
+		hc.telemetry = newTelemetry(hc.tracerProvider, hc.meterProvider)
+	}
+
+	return createTicker(interval, check, hc.telemetry, hc.getClock())
+}
+
 // Start begins each ticker, this is used to run the health checks on dependent apps
 // It also starts a go-routine to check the state after the criticalErrorTimeout has expired
 // until the app is fully started, to make sure the state is updated accordingly without relying on the http Handle being called
 // takes argument context and should utilise contextWithCancel
 // Passing a nil context will cause errors during stop/app shutdown
-func (hc *HealthCheck) Start(ctx context.Context) {
+// A health check that has been stopped may be started again. ErrAlreadyRunning is returned if it is already running.
+func (hc *HealthCheck) Start(ctx context.Context) error {
+	hc.lifecycleMutex.Lock()
+	defer hc.lifecycleMutex.Unlock()
+
+	hc.statusLock.Lock()
+	if hc.isRunning() {
+		hc.statusLock.Unlock()
+		return ErrAlreadyRunning
+	}
+	// the tickers of a stopped health check cannot be restarted, so new ones are created for every check
+	if hc.lifecycle == lifecycleStopped {
+		for i, ticker := range hc.tickers {
+			hc.tickers[i] = hc.createCheckTicker(ticker.check)
+		}
+	}
+	// an app that was drained before being stopped is no longer shutting down once it is restarted
+	wasDraining := hc.lifecycle == lifecycleStopped && hc.Draining
+	if wasDraining {
+		hc.Draining = false
+	}
+	hc.lifecycle = lifecycleRunning
 	hc.context = ctx
-	hc.StartTime = time.Now().UTC()
-	for _, ticker := range hc.tickers {
+	hc.StartTime = hc.getClock().Now().UTC()
+	hc.scheduleEscalation()
+	tickers := append([]*ticker{}, hc.tickers...)
+	hc.statusLock.Unlock()
+
+	if wasDraining {
+		log.Info(ctx, "health check is no longer draining")
+		hc.healthChangeCallback()
+	}
+	for _, ticker := range tickers {
 		ticker.start(ctx, hc.tickersWaitgroup)
 	}
 	hc.startTracker(ctx)
+	return nil
 }
 
 // startTracker creates a new go-routine to keep track of the app status after the critical timeout has expired
@@ -130,23 +378,17 @@ func (hc *HealthCheck) startTracker(ctx context.Context) {
 	go func(ctx context.Context) {
 		defer hc.tickersWaitgroup.Done()
 		for {
-			delay := time.NewTimer(hc.criticalErrorTimeout)
+			delay := hc.getClock().NewTimer(hc.criticalErrorTimeout)
 			select {
-			case <-delay.C:
+			case <-delay.C():
 				hc.loopAppStartingUp(ctx)
 			case <-ctx.Done():
 				// Ensure timer is stopped and its resources are freed
-				if !delay.Stop() {
-					// if the timer has been stopped then read from the channel
-					<-delay.C
-				}
+				delay.Stop()
 				return
 			case <-hc.stopper:
 				// Ensure timer is stopped and its resources are freed
-				if !delay.Stop() {
-					// if the timer has been stopped then read from the channel
-					<-delay.C
-				}
+				delay.Stop()
 				return
 			}
 		}
@@ -159,12 +401,12 @@ func (hc *HealthCheck) startTracker(ctx context.Context) {
 func (hc *HealthCheck) loopAppStartingUp(ctx context.Context) {
 	intervalWithJitter := calcIntervalWithJitter(hc.interval / 10)
 	for {
-		delay := time.NewTimer(intervalWithJitter)
+		delay := hc.getClock().NewTimer(intervalWithJitter)
 		select {
-		case <-delay.C:
+		case <-delay.C():
 			hc.statusLock.Lock()
 
-			now := time.Now().UTC()
+			now := hc.getClock().Now().UTC()
 			hc.Uptime = now.Sub(hc.StartTime) / time.Millisecond
 
 			if hc.isAppStartingUp() {
@@ -181,30 +423,44 @@ func (hc *HealthCheck) loopAppStartingUp(ctx context.Context) {
 
 		case <-ctx.Done():
 			// Ensure timer is stopped and its resources are freed
-			if !delay.Stop() {
-				// if the timer has been stopped then read from the channel
-				<-delay.C
-			}
+			delay.Stop()
 			return
 		case <-hc.stopper:
 			// Ensure timer is stopped and its resources are freed
-			if !delay.Stop() {
-				// if the timer has been stopped then read from the channel
-				<-delay.C
-			}
+			delay.Stop()
 			return
 		}
 	}
 }
 
 // Stop will cancel all tickers and thus stop all health checks
-// It also stops the go-routine that was created in start, if it is still alive.
-func (hc *HealthCheck) Stop() {
-	for _, ticker := range hc.tickers {
+// It also stops the go-routine that was created in start, if it is still alive, and the go-routines that notify the
+// subscribers, which are kept subscribed and are notified from new go-routines on their next health update.
+// ErrNotRunning is returned if the health check has not been started, or has already been stopped.
+func (hc *HealthCheck) Stop() error {
+	hc.lifecycleMutex.Lock()
+	defer hc.lifecycleMutex.Unlock()
+
+	hc.statusLock.Lock()
+	if !hc.isRunning() {
+		hc.statusLock.Unlock()
+		return ErrNotRunning
+	}
+	hc.lifecycle = lifecycleStopped
+	hc.stopEscalation()
+	tickers := append([]*ticker{}, hc.tickers...)
+	hc.statusLock.Unlock()
+
+	for _, ticker := range tickers {
 		ticker.stop()
 	}
 	close(hc.stopper)
 	hc.tickersWaitgroup.Wait()
+
+	hc.subsMutex.Lock()
+	hc.closeDispatchers()
+	hc.subsMutex.Unlock()
+	return nil
 }
 
 // GetStatus returns the current status in a thread-safe way
@@ -215,7 +471,8 @@ func (hc *HealthCheck) GetStatus() string {
 }
 
 // SetStatus sets the current status in a thread-safe way,
-// returns the new status
+// returns the new status.
+// The status is recalculated from the checks on the next request or state change, use SetOverride to force it instead.
 func (hc *HealthCheck) SetStatus(newStatus string) string {
 	hc.statusLock.Lock()
 	defer hc.statusLock.Unlock()
@@ -3,11 +3,14 @@ package healthcheck
 import (
 	"context"
 	"errors"
+	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ONSdigital/dp-healthcheck/healthcheck/mock"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -217,6 +220,8 @@ func TestLoopAppStartingUp(t *testing.T) {
 			interval:             interval,
 			tickersWaitgroup:     &sync.WaitGroup{},
 			statusLock:           &sync.RWMutex{},
+			subsMutex:            &sync.Mutex{},
+			lifecycleMutex:       &sync.Mutex{},
 			Checks:               checks,
 		}
 	}
@@ -224,8 +229,10 @@ func TestLoopAppStartingUp(t *testing.T) {
 	Convey("Given a healthcheck with a checker that has already run before", t, func() {
 		hc := testHc(&Check{
 			state: &CheckState{
-				mutex:       &sync.RWMutex{},
-				lastChecked: &t0,
+				mutex:         &sync.RWMutex{},
+				status:        StatusCritical,
+				lastChecked:   &t0,
+				criticalSince: &t0,
 			},
 		})
 
@@ -328,6 +335,364 @@ func TestAddCheck(t *testing.T) {
 	})
 }
 
+func TestAddCheckWithOptions(t *testing.T) {
+	okChecker := func(ctx context.Context, state *CheckState) error {
+		return state.Update(StatusOK, "ok", 0)
+	}
+
+	Convey("Given a Health Check with a check that overrides the interval", t, func() {
+		hc := New(version, criticalTimeout, interval)
+		_, err := hc.AddAndGetCheck("check 1", okChecker, WithInterval(time.Hour))
+		So(err, ShouldBeNil)
+
+		Convey("Then the ticker is created with the check interval instead of the health check interval", func() {
+			So(hc.tickers, ShouldHaveLength, 1)
+			So(hc.tickers[0].interval, ShouldBeGreaterThan, interval)
+			So(hc.tickers[0].interval, ShouldAlmostEqual, time.Hour, time.Duration(getMaxJitter(time.Hour)))
+		})
+	})
+
+	Convey("Given a Health Check with a checker that runs for longer than its timeout", t, func() {
+		checkerCancelled := make(chan struct{})
+		slowChecker := func(ctx context.Context, state *CheckState) error {
+			<-ctx.Done()
+			close(checkerCancelled)
+			return ctx.Err()
+		}
+
+		hc := New(version, criticalTimeout, time.Hour)
+		check, err := hc.AddAndGetCheck("slow check", slowChecker, WithTimeout(interval))
+		So(err, ShouldBeNil)
+
+		Convey("When the health check is started", func() {
+			hc.Start(context.Background())
+			defer hc.Stop()
+
+			Convey("Then the checker context is cancelled and the check is recorded as CRITICAL", func() {
+				<-checkerCancelled
+				time.Sleep(interval / 10)
+				So(check.state.Status(), ShouldEqual, StatusCritical)
+				So(check.state.Message(), ShouldEqual, "check timed out after 100ms")
+				So(check.state.LastFailure(), ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given a Health Check with a checker that reads the state of its previous run", t, func() {
+		var lastChecked, lastSuccess *time.Time
+		checker := func(ctx context.Context, state *CheckState) error {
+			lastChecked, lastSuccess = state.LastChecked(), state.LastSuccess()
+			return state.Update(StatusOK, "ok", 0)
+		}
+
+		hc := New(version, criticalTimeout, time.Hour)
+		check, err := hc.AddAndGetCheck("check", checker)
+		So(err, ShouldBeNil)
+
+		Convey("When the check is run twice", func() {
+			ticker := createTicker(time.Hour, check, hc.telemetry, hc.getClock())
+			for i := 0; i < 2; i++ {
+				wg := &sync.WaitGroup{}
+				wg.Add(1)
+				ticker.runCheck(context.Background(), wg)
+			}
+
+			Convey("Then the second run sees the result of the first one", func() {
+				So(lastChecked, ShouldNotBeNil)
+				So(lastSuccess, ShouldNotBeNil)
+			})
+		})
+	})
+
+	Convey("Given a Health Check with a checker that ignores its context and runs for longer than its timeout", t, func() {
+		release := make(chan struct{})
+		var runs int32
+		stuckChecker := func(ctx context.Context, state *CheckState) error {
+			atomic.AddInt32(&runs, 1)
+			<-release
+			return state.Update(StatusOK, "ok", 0)
+		}
+
+		hc := New(version, criticalTimeout, time.Hour)
+		check, err := hc.AddAndGetCheck("stuck check", stuckChecker, WithTimeout(interval))
+		So(err, ShouldBeNil)
+
+		Convey("When the check is run", func() {
+			wg := &sync.WaitGroup{}
+			wg.Add(1)
+			ticker := createTicker(time.Hour, check, hc.telemetry, hc.getClock())
+			ticker.runCheck(context.Background(), wg)
+
+			Convey("Then the run ends once the timeout expires and the check is recorded as CRITICAL", func() {
+				So(check.state.Status(), ShouldEqual, StatusCritical)
+				So(check.state.Message(), ShouldEqual, "check timed out after 100ms")
+			})
+
+			Convey("Then the check stays CRITICAL once the checker eventually returns", func() {
+				close(release)
+				time.Sleep(interval / 10)
+				So(check.state.Status(), ShouldEqual, StatusCritical)
+				So(check.state.Message(), ShouldEqual, "check timed out after 100ms")
+			})
+
+			Convey("Then the next run is skipped while the checker has not returned", func() {
+				wg.Add(1)
+				ticker.runCheck(context.Background(), wg)
+				So(atomic.LoadInt32(&runs), ShouldEqual, 1)
+
+				Convey("And the check is run again once the checker has returned", func() {
+					close(release)
+					time.Sleep(interval / 10)
+					wg.Add(1)
+					ticker.runCheck(context.Background(), wg)
+					So(atomic.LoadInt32(&runs), ShouldEqual, 2)
+					So(check.state.Status(), ShouldEqual, StatusOK)
+				})
+			})
+		})
+	})
+
+	Convey("Given a Health Check with a checker that returns exactly as its timeout expires", t, func() {
+		lateChecker := func(ctx context.Context, state *CheckState) error {
+			<-ctx.Done()
+			return state.Update(StatusOK, "ok", 0)
+		}
+
+		hc := New(version, criticalTimeout, time.Hour)
+		check, err := hc.AddAndGetCheck("late check", lateChecker, WithTimeout(interval))
+		So(err, ShouldBeNil)
+
+		Convey("When the check is run", func() {
+			wg := &sync.WaitGroup{}
+			wg.Add(1)
+			createTicker(time.Hour, check, hc.telemetry, hc.getClock()).runCheck(context.Background(), wg)
+
+			Convey("Then the timeout is recorded even though the checker returned without an error", func() {
+				So(check.state.Status(), ShouldEqual, StatusCritical)
+				So(check.state.Message(), ShouldEqual, "check timed out after 100ms")
+			})
+		})
+	})
+
+	Convey("Given a Health Check with a checker that completes within its timeout", t, func() {
+		hc := New(version, criticalTimeout, time.Hour)
+		check, err := hc.AddAndGetCheck("fast check", okChecker, WithTimeout(time.Second))
+		So(err, ShouldBeNil)
+
+		Convey("When the health check is started", func() {
+			hc.Start(context.Background())
+			defer hc.Stop()
+
+			Convey("Then the check state is set by the checker", func() {
+				time.Sleep(interval)
+				So(check.state.Status(), ShouldEqual, StatusOK)
+				So(check.state.Message(), ShouldEqual, "ok")
+			})
+
+			Convey("Then the run is recorded in the check metrics", func() {
+				time.Sleep(interval)
+				So(check.metrics.snapshot().runs, ShouldEqual, 1)
+				So(check.metrics.snapshot().failures, ShouldEqual, 0)
+			})
+		})
+	})
+
+	Convey("Given a Health Check with a check that has an initial delay", t, func() {
+		hc := New(version, criticalTimeout, time.Hour)
+		check, err := hc.AddAndGetCheck("delayed check", okChecker, WithInitialDelay(2*interval))
+		So(err, ShouldBeNil)
+
+		Convey("When the health check is started", func() {
+			hc.Start(context.Background())
+			defer hc.Stop()
+
+			Convey("Then the check does not run until the delay has passed", func() {
+				time.Sleep(interval)
+				So(check.hasRun(), ShouldBeFalse)
+
+				time.Sleep(2 * interval)
+				So(check.hasRun(), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestRemoveCheck(t *testing.T) {
+	cf := func(ctx context.Context, state *CheckState) error {
+		return state.Update(StatusOK, "ok", 0)
+	}
+	criticalCf := func(ctx context.Context, state *CheckState) error {
+		return state.Update(StatusCritical, "down", 0)
+	}
+
+	Convey("Given a started Health Check with an OK check and a CRITICAL check, and a subscriber to both", t, func() {
+		hc := New(version, criticalTimeout, time.Hour)
+		okCheck, err := hc.AddAndGetCheck("ok check", cf)
+		So(err, ShouldBeNil)
+		criticalCheck, err := hc.AddAndGetCheck("critical check", criticalCf)
+		So(err, ShouldBeNil)
+		criticalSince := time.Now().UTC().Add(-2 * criticalTimeout)
+		criticalCheck.state.criticalSince = &criticalSince
+
+		sub := &mock.SubscriberMock{OnHealthUpdateFunc: func(status string) {}}
+		hc.Subscribe(sub, criticalCheck)
+		onlyCritical := &mock.SubscriberMock{OnHealthUpdateFunc: func(status string) {}}
+		hc.Subscribe(onlyCritical, criticalCheck)
+		hc.Subscribe(sub, okCheck)
+
+		hc.Start(context.Background())
+		defer hc.Stop()
+		time.Sleep(interval)
+
+		Convey("When the CRITICAL check is removed", func() {
+			err := hc.RemoveCheck(criticalCheck)
+			So(err, ShouldBeNil)
+
+			Convey("Then the check and its ticker are removed", func() {
+				So(hc.Checks, ShouldResemble, []*Check{okCheck})
+				So(hc.tickers, ShouldHaveLength, 1)
+				So(hc.tickers[0].check, ShouldEqual, okCheck)
+			})
+
+			Convey("Then the check is removed from every subscriber, and subscribers left without checks are removed", func() {
+				So(hc.subscribers, ShouldHaveLength, 1)
+				So(hc.subscribers[sub], ShouldResemble, map[*Check]struct{}{okCheck: {}})
+			})
+
+			Convey("Then the app status is updated straight away", func() {
+				So(hc.GetStatus(), ShouldEqual, StatusOK)
+			})
+
+			Convey("Then removing it again fails", func() {
+				So(hc.RemoveCheck(criticalCheck), ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the check is removed by name", func() {
+			err := hc.RemoveCheckByName("ok check")
+			So(err, ShouldBeNil)
+
+			Convey("Then only the checks with that name are removed", func() {
+				So(hc.Checks, ShouldResemble, []*Check{criticalCheck})
+				So(hc.tickers, ShouldHaveLength, 1)
+				So(hc.subscribers, ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When a check that does not exist is removed by name", func() {
+			err := hc.RemoveCheckByName("unknown")
+
+			Convey("Then an error is returned and no checks are removed", func() {
+				So(err, ShouldNotBeNil)
+				So(hc.Checks, ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a Health Check that has not been started, with one check", t, func() {
+		hc := New(version, criticalTimeout, interval)
+		check, err := hc.AddAndGetCheck("check", cf)
+		So(err, ShouldBeNil)
+
+		Convey("When the check is removed and the health check is started", func() {
+			So(hc.RemoveCheck(check), ShouldBeNil)
+			hc.Start(context.Background())
+			defer hc.Stop()
+			time.Sleep(2 * interval)
+
+			Convey("Then the removed check is not run", func() {
+				So(check.hasRun(), ShouldBeFalse)
+				So(hc.tickers, ShouldBeEmpty)
+			})
+		})
+	})
+	Convey("Given a Health Check with many checks", t, func() {
+		hc := New(version, criticalTimeout, time.Hour)
+		for i := 0; i < 50; i++ {
+			_, err := hc.AddAndGetCheck(fmt.Sprintf("check %d", i), cf)
+			So(err, ShouldBeNil)
+		}
+
+		Convey("When subscribers subscribe to all the checks while the checks are removed", func() {
+			wg := &sync.WaitGroup{}
+			errs := make(chan error, 50)
+			for i := 0; i < 50; i++ {
+				wg.Add(2)
+				go func() {
+					defer wg.Done()
+					hc.SubscribeAll(&mock.SubscriberMock{OnHealthUpdateFunc: func(status string) {}})
+				}()
+				go func(i int) {
+					defer wg.Done()
+					errs <- hc.RemoveCheckByName(fmt.Sprintf("check %d", i))
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+
+			Convey("Then every check is removed, without any data race", func() {
+				for err := range errs {
+					So(err, ShouldBeNil)
+				}
+				So(hc.Checks, ShouldBeEmpty)
+			})
+		})
+	})
+}
+
+func TestReplaceCheck(t *testing.T) {
+	cf := func(ctx context.Context, state *CheckState) error {
+		return state.Update(StatusOK, "ok", 0)
+	}
+	criticalCf := func(ctx context.Context, state *CheckState) error {
+		return state.Update(StatusCritical, "down", 0)
+	}
+
+	Convey("Given a started Health Check with a CRITICAL check that has a subscriber", t, func() {
+		hc := New(version, criticalTimeout, time.Hour)
+		oldCheck, err := hc.AddAndGetCheck("check", criticalCf)
+		So(err, ShouldBeNil)
+		criticalSince := time.Now().UTC().Add(-2 * criticalTimeout)
+		oldCheck.state.criticalSince = &criticalSince
+
+		sub := &mock.SubscriberMock{OnHealthUpdateFunc: func(status string) {}}
+		hc.Subscribe(sub, oldCheck)
+
+		hc.Start(context.Background())
+		defer hc.Stop()
+		time.Sleep(interval)
+		So(hc.GetStatus(), ShouldEqual, StatusCritical)
+
+		Convey("When the check is replaced with an OK checker", func() {
+			check, err := hc.ReplaceCheck("check", cf, WithInterval(time.Minute))
+			So(err, ShouldBeNil)
+			time.Sleep(interval)
+
+			Convey("Then the new check takes the place of the old one", func() {
+				So(hc.Checks, ShouldResemble, []*Check{check})
+				So(hc.tickers, ShouldHaveLength, 1)
+				So(hc.tickers[0].check, ShouldEqual, check)
+				So(hc.subscribers[sub], ShouldResemble, map[*Check]struct{}{check: {}})
+			})
+
+			Convey("Then the new check is run and the app status is updated", func() {
+				So(check.hasRun(), ShouldBeTrue)
+				So(hc.GetStatus(), ShouldEqual, StatusOK)
+			})
+		})
+
+		Convey("When a check that does not exist is replaced", func() {
+			check, err := hc.ReplaceCheck("unknown", cf)
+
+			Convey("Then an error is returned and the checks are unchanged", func() {
+				So(err, ShouldNotBeNil)
+				So(check, ShouldBeNil)
+				So(hc.Checks, ShouldResemble, []*Check{oldCheck})
+			})
+		})
+	})
+}
+
 func TestNewVersionInfo(t *testing.T) {
 	Convey("Create a new versionInfo object", t, func() {
 		buildTime := "0"
@@ -0,0 +1,172 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// defaultHistorySize is the number of state transitions kept for each check if no history size is provided
+const defaultHistorySize = 20
+
+// HistoryEntry represents a transition of a check from one status to another
+type HistoryEntry struct {
+	Time       time.Time     `json:"time"`
+	OldStatus  string        `json:"old_status"`
+	NewStatus  string        `json:"new_status"`
+	Message    string        `json:"message"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// checkHistoryJSON represents the history of a check for use with json marshal
+type checkHistoryJSON struct {
+	Name    string         `json:"name"`
+	History []HistoryEntry `json:"history"`
+}
+
+// historyResponse represents the body returned by the history handler
+type historyResponse struct {
+	Checks []checkHistoryJSON `json:"checks"`
+}
+
+// history is a ring buffer of the most recent state transitions of a check
+type history struct {
+	entries []HistoryEntry
+	next    int
+	full    bool
+}
+
+// newHistory returns a pointer to a new instantiated history that keeps up to size entries
+func newHistory(size int) *history {
+	return &history{
+		entries: make([]HistoryEntry, size),
+	}
+}
+
+// add adds an entry to the history, overwriting the oldest entry if the history is full
+func (h *history) add(entry HistoryEntry) {
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// setDuration sets the duration of the entries added since the provided start of a run that do not have one yet
+func (h *history) setDuration(start time.Time) {
+	n := h.next
+	if h.full {
+		n = len(h.entries)
+	}
+	for i := 1; i <= n; i++ {
+		entry := &h.entries[(h.next-i+len(h.entries))%len(h.entries)]
+		if entry.Time.Before(start) {
+			return
+		}
+		if entry.Duration == 0 {
+			entry.Duration = entry.Time.Sub(start)
+		}
+	}
+}
+
+// list returns a copy of the entries in the history, oldest first
+func (h *history) list() []HistoryEntry {
+	if !h.full {
+		return append([]HistoryEntry{}, h.entries[:h.next]...)
+	}
+	return append(append([]HistoryEntry{}, h.entries[h.next:]...), h.entries[:h.next]...)
+}
+
+// WithHistorySize sets the number of state transitions kept in the history of the check.
+// Defaults to 20 if not provided, or if the size is not positive.
+func WithHistorySize(size int) CheckOption {
+	return func(c *Check) {
+		c.state.historySize = size
+	}
+}
+
+// History returns the most recent state transitions of the check, oldest first
+func (c *Check) History() []HistoryEntry {
+	c.state.mutex.RLock()
+	defer c.state.mutex.RUnlock()
+
+	if c.state.history == nil {
+		return []HistoryEntry{}
+	}
+	return c.state.history.list()
+}
+
+// HistoryHandler responds to an http request with the recent state transitions of the checks.
+// The checks may be filtered with the 'check' query parameter, providing a comma separated list of check names,
+// and the transitions may be filtered with the 'from' and 'to' query parameters, providing RFC3339 times.
+func (hc *HealthCheck) HistoryHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	query := req.URL.Query()
+
+	names := map[string]bool{}
+	for _, param := range query["check"] {
+		for _, name := range strings.Split(param, ",") {
+			names[name] = true
+		}
+	}
+
+	from, err := parseTimeParam(query.Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from parameter, must be an RFC3339 time", http.StatusBadRequest)
+		return
+	}
+	to, err := parseTimeParam(query.Get("to"))
+	if err != nil {
+		http.Error(w, "invalid to parameter, must be an RFC3339 time", http.StatusBadRequest)
+		return
+	}
+
+	hc.statusLock.RLock()
+	checks := append([]*Check{}, hc.Checks...)
+	hc.statusLock.RUnlock()
+
+	resp := historyResponse{Checks: []checkHistoryJSON{}}
+	for _, check := range checks {
+		name := check.state.Name()
+		if len(names) > 0 && !names[name] {
+			continue
+		}
+
+		entries := []HistoryEntry{}
+		for _, entry := range check.History() {
+			if !from.IsZero() && entry.Time.Before(from) {
+				continue
+			}
+			if !to.IsZero() && entry.Time.After(to) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		resp.Checks = append(resp.Checks, checkHistoryJSON{Name: name, History: entries})
+	}
+
+	b, err := json.Marshal(resp)
+	if err != nil {
+		log.Error(ctx, "failed to marshal json", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(b); err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err)
+		return
+	}
+}
+
+// parseTimeParam parses an RFC3339 time query parameter, returning the zero time if it is empty
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
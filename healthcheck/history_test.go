@@ -0,0 +1,207 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHistory(t *testing.T) {
+	Convey("Given a history with a size of 3", t, func() {
+		h := newHistory(3)
+
+		Convey("Then it is empty", func() {
+			So(h.list(), ShouldBeEmpty)
+		})
+
+		Convey("When 2 entries are added", func() {
+			h.add(HistoryEntry{NewStatus: "1"})
+			h.add(HistoryEntry{NewStatus: "2"})
+
+			Convey("Then both entries are listed, oldest first", func() {
+				entries := h.list()
+				So(entries, ShouldHaveLength, 2)
+				So(entries[0].NewStatus, ShouldEqual, "1")
+				So(entries[1].NewStatus, ShouldEqual, "2")
+			})
+		})
+
+		Convey("When 5 entries are added", func() {
+			for _, status := range []string{"1", "2", "3", "4", "5"} {
+				h.add(HistoryEntry{NewStatus: status})
+			}
+
+			Convey("Then only the 3 most recent entries are listed, oldest first", func() {
+				entries := h.list()
+				So(entries, ShouldHaveLength, 3)
+				So(entries[0].NewStatus, ShouldEqual, "3")
+				So(entries[1].NewStatus, ShouldEqual, "4")
+				So(entries[2].NewStatus, ShouldEqual, "5")
+			})
+		})
+	})
+}
+
+func TestCheckHistory(t *testing.T) {
+	Convey("Given a check with a history size of 2", t, func() {
+		check, err := NewCheck("check", func(ctx context.Context, state *CheckState) error { return nil }, WithHistorySize(2))
+		So(err, ShouldBeNil)
+
+		Convey("Then the history is empty before any update", func() {
+			So(check.History(), ShouldBeEmpty)
+		})
+
+		Convey("When the state is updated with a repeated status and then a different one", func() {
+			before := time.Now().UTC()
+			So(check.state.Update(StatusOK, "ok", 200), ShouldBeNil)
+			So(check.state.Update(StatusOK, "still ok", 200), ShouldBeNil)
+			So(check.state.Update(StatusCritical, "failed", 500), ShouldBeNil)
+			after := time.Now().UTC()
+
+			Convey("Then only the state transitions are recorded", func() {
+				entries := check.History()
+				So(entries, ShouldHaveLength, 2)
+				So(entries[0].OldStatus, ShouldEqual, "")
+				So(entries[0].NewStatus, ShouldEqual, StatusOK)
+				So(entries[0].Message, ShouldEqual, "ok")
+				So(entries[0].StatusCode, ShouldEqual, 200)
+				So(entries[1].OldStatus, ShouldEqual, StatusOK)
+				So(entries[1].NewStatus, ShouldEqual, StatusCritical)
+				So(entries[1].Message, ShouldEqual, "failed")
+				So(entries[1].StatusCode, ShouldEqual, 500)
+				So(entries[1].Time, ShouldHappenOnOrBetween, before, after)
+			})
+
+			Convey("Then the oldest transition is dropped when the history is full", func() {
+				So(check.state.Update(StatusOK, "recovered", 200), ShouldBeNil)
+				entries := check.History()
+				So(entries, ShouldHaveLength, 2)
+				So(entries[0].NewStatus, ShouldEqual, StatusCritical)
+				So(entries[1].NewStatus, ShouldEqual, StatusOK)
+			})
+		})
+
+		Convey("When the state changes during a run of the checker", func() {
+			started := time.Now().UTC().Add(-time.Second)
+			So(check.state.Update(StatusWarning, "slow", 0), ShouldBeNil)
+			check.state.setRunDuration(started)
+
+			Convey("Then the duration of the run so far is recorded", func() {
+				entries := check.History()
+				So(entries, ShouldHaveLength, 1)
+				So(entries[0].Duration, ShouldBeGreaterThanOrEqualTo, time.Second)
+			})
+		})
+
+		Convey("When a short run of the checker overlaps with a long one", func() {
+			longStarted := time.Now().UTC().Add(-time.Hour)
+			shortStarted := time.Now().UTC()
+			So(check.state.Update(StatusCritical, "failed", 0), ShouldBeNil)
+			check.state.setRunDuration(shortStarted)
+			So(check.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+			check.state.setRunDuration(longStarted)
+
+			Convey("Then the duration of each transition is measured from the start of its own run", func() {
+				entries := check.History()
+				So(entries, ShouldHaveLength, 2)
+				So(entries[0].NewStatus, ShouldEqual, StatusCritical)
+				So(entries[0].Duration, ShouldBeLessThan, time.Minute)
+				So(entries[1].NewStatus, ShouldEqual, StatusOK)
+				So(entries[1].Duration, ShouldBeGreaterThanOrEqualTo, time.Hour)
+			})
+		})
+
+		Convey("When a run of the checker starts after the last transition", func() {
+			So(check.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+			check.state.setRunDuration(time.Now().UTC().Add(time.Second))
+
+			Convey("Then the duration of the transition is left as is", func() {
+				entries := check.History()
+				So(entries, ShouldHaveLength, 1)
+				So(entries[0].Duration, ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func TestHistoryHandler(t *testing.T) {
+	Convey("Given a healthcheck with 2 checks that have changed state", t, func() {
+		hc := getTestHealthCheck(time.Now().UTC(), 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{{name: "mongodb"}, {name: "kafka"}}, false)
+
+		So(hc.Checks[0].state.Update(StatusCritical, "mongodb is down", 0), ShouldBeNil)
+		time.Sleep(1100 * time.Millisecond) // RFC3339 has a resolution of seconds
+		So(hc.Checks[0].state.Update(StatusOK, "mongodb is ok", 0), ShouldBeNil)
+		firstChange := hc.Checks[0].History()[0].Time
+		secondChange := hc.Checks[0].History()[1].Time
+		So(hc.Checks[1].state.Update(StatusOK, "kafka is ok", 0), ShouldBeNil)
+
+		callHandler := func(query url.Values) (int, historyResponse) {
+			w := httptest.NewRecorder()
+			hc.HistoryHandler(w, httptest.NewRequest(http.MethodGet, "/health/history?"+query.Encode(), nil))
+
+			var resp historyResponse
+			if w.Code == http.StatusOK {
+				So(json.Unmarshal(w.Body.Bytes(), &resp), ShouldBeNil)
+			}
+			return w.Code, resp
+		}
+
+		Convey("When the handler is called without any filters", func() {
+			code, resp := callHandler(url.Values{})
+
+			Convey("Then the history of all checks is returned", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(resp.Checks, ShouldHaveLength, 2)
+				So(resp.Checks[0].Name, ShouldEqual, "mongodb")
+				So(resp.Checks[0].History, ShouldHaveLength, 2)
+				So(resp.Checks[1].Name, ShouldEqual, "kafka")
+				So(resp.Checks[1].History, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When the handler is called filtering by check name", func() {
+			code, resp := callHandler(url.Values{"check": {"kafka"}})
+
+			Convey("Then only the history of that check is returned", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(resp.Checks, ShouldHaveLength, 1)
+				So(resp.Checks[0].Name, ShouldEqual, "kafka")
+			})
+		})
+
+		Convey("When the handler is called filtering by time range", func() {
+			code, resp := callHandler(url.Values{"from": {secondChange.Truncate(time.Second).Format(time.RFC3339)}})
+
+			Convey("Then only the transitions in that range are returned", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(resp.Checks[0].History, ShouldHaveLength, 1)
+				So(resp.Checks[0].History[0].NewStatus, ShouldEqual, StatusOK)
+			})
+
+			code, resp = callHandler(url.Values{"to": {firstChange.Truncate(time.Second).Add(time.Second).Format(time.RFC3339)}, "check": {"mongodb,kafka"}})
+
+			Convey("Then only the transitions before the end of the range are returned", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(resp.Checks, ShouldHaveLength, 2)
+				So(resp.Checks[0].History, ShouldHaveLength, 1)
+				So(resp.Checks[0].History[0].NewStatus, ShouldEqual, StatusCritical)
+				So(resp.Checks[1].History, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the handler is called with an invalid time", func() {
+			code, _ := callHandler(url.Values{"from": {"yesterday"}})
+
+			Convey("Then a bad request status is returned", func() {
+				So(code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+}
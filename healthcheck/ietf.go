@@ -0,0 +1,138 @@
+package healthcheck
+
+import (
+	"mime"
+	"strings"
+	"time"
+)
+
+// ietfContentType is the content type of the IETF health check response format
+const ietfContentType = "application/health+json"
+
+// A list of the statuses used by the IETF health check response format
+const (
+	ietfStatusPass = "pass"
+	ietfStatusWarn = "warn"
+	ietfStatusFail = "fail"
+)
+
+// ResponseFormat defines the format of the health check response returned by Handler
+type ResponseFormat int
+
+// A list of possible response formats
+const (
+	// FormatONS is the format defined by the ONS health check specification. This is the default.
+	FormatONS ResponseFormat = iota
+	// FormatIETF is the format defined by the IETF health check response format draft (application/health+json)
+	FormatIETF
+)
+
+// ietfResponse represents the health of the app in the IETF health check response format
+type ietfResponse struct {
+	Status    string                 `json:"status"`
+	Version   string                 `json:"version,omitempty"`
+	ReleaseID string                 `json:"releaseId,omitempty"`
+	Output    string                 `json:"output,omitempty"`
+	Checks    map[string][]ietfCheck `json:"checks"`
+}
+
+// ietfCheck represents a measurement of a check in the IETF health check response format
+type ietfCheck struct {
+	ComponentID   string     `json:"componentId"`
+	Status        string     `json:"status"`
+	ObservedValue *float64   `json:"observedValue,omitempty"`
+	ObservedUnit  string     `json:"observedUnit,omitempty"`
+	Time          *time.Time `json:"time,omitempty"`
+	Output        string     `json:"output,omitempty"`
+}
+
+// WithResponseFormat sets the format of the response returned by Handler when the request does not ask for a specific one
+// with its Accept header. Defaults to FormatONS.
+func WithResponseFormat(format ResponseFormat) Option {
+	return func(hc *HealthCheck) {
+		hc.responseFormat = format
+	}
+}
+
+// getResponseFormat returns the response format requested by the provided Accept header,
+// or the configured response format if no supported format is requested
+func (hc *HealthCheck) getResponseFormat(accept string) ResponseFormat {
+	requestsJSON := false
+	for _, value := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(value)
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case ietfContentType:
+			return FormatIETF
+		case "application/json":
+			requestsJSON = true
+		}
+	}
+	if requestsJSON {
+		return FormatONS
+	}
+	return hc.responseFormat
+}
+
+// getIETFResponse returns the health of the app in the IETF health check response format.
+// The caller must hold the statusLock and have updated the app status.
+func (hc *HealthCheck) getIETFResponse() ietfResponse {
+	resp := ietfResponse{
+		Status:    toIETFStatus(hc.Status),
+		Version:   hc.Version.Version,
+		ReleaseID: hc.Version.GitCommit,
+		Checks:    map[string][]ietfCheck{},
+	}
+	if hc.Draining {
+		resp.Output = drainingReason
+	} else if hc.Override != nil {
+		resp.Output = hc.Override.Reason
+	} else if resp.Status != ietfStatusPass && hc.isAppStartingUp() {
+		resp.Output = "a dependency is still starting up"
+	}
+
+	for _, check := range hc.Checks {
+		state := check.state
+		name := state.Name()
+
+		c := ietfCheck{
+			ComponentID: name,
+			Status:      toIETFStatus(state.reportedStatus()),
+			Time:        state.LastChecked(),
+		}
+		if override := state.Override(); override != nil {
+			c.Output = override.Reason
+		} else if c.Status != ietfStatusPass {
+			c.Output = state.Message()
+			if c.Time == nil {
+				c.Output = "check has not run yet"
+			}
+		}
+		if check.metrics != nil {
+			if m := check.metrics.snapshot(); m.runs > 0 {
+				observedValue := float64(m.lastDuration) / float64(time.Millisecond)
+				c.ObservedValue = &observedValue
+				c.ObservedUnit = "ms"
+			}
+		}
+
+		key := name + ":responseTime"
+		resp.Checks[key] = append(resp.Checks[key], c)
+	}
+
+	return resp
+}
+
+// toIETFStatus returns the IETF health check response format status for the provided status
+func toIETFStatus(status string) string {
+	switch status {
+	case StatusOK:
+		return ietfStatusPass
+	case StatusCritical:
+		return ietfStatusFail
+	default:
+		return ietfStatusWarn
+	}
+}
@@ -0,0 +1,150 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetResponseFormat(t *testing.T) {
+	Convey("Given a healthcheck with the default response format", t, func() {
+		hc := getTestHealthCheck(time.Now().UTC(), 10*time.Minute)
+
+		Convey("Then the IETF format is used only if it is requested", func() {
+			So(hc.getResponseFormat(""), ShouldEqual, FormatONS)
+			So(hc.getResponseFormat("*/*"), ShouldEqual, FormatONS)
+			So(hc.getResponseFormat("application/json"), ShouldEqual, FormatONS)
+			So(hc.getResponseFormat("application/health+json"), ShouldEqual, FormatIETF)
+			So(hc.getResponseFormat("application/json;q=0.5, application/health+json"), ShouldEqual, FormatIETF)
+		})
+	})
+
+	Convey("Given a healthcheck with the IETF response format", t, func() {
+		hc := getTestHealthCheck(time.Now().UTC(), 10*time.Minute)
+		WithResponseFormat(FormatIETF)(&hc)
+
+		Convey("Then the ONS format is used only if plain json is requested", func() {
+			So(hc.getResponseFormat(""), ShouldEqual, FormatIETF)
+			So(hc.getResponseFormat("*/*"), ShouldEqual, FormatIETF)
+			So(hc.getResponseFormat("application/json"), ShouldEqual, FormatONS)
+		})
+	})
+}
+
+func TestIETFHandler(t *testing.T) {
+	t0 := time.Now().UTC()
+	t10 := t0.Add(-10 * time.Minute)
+	t20 := t0.Add(-20 * time.Minute)
+
+	callHandler := func(hc *HealthCheck) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("Accept", "application/health+json")
+		w := httptest.NewRecorder()
+		hc.Handler(w, req)
+
+		body := map[string]interface{}{}
+		So(json.Unmarshal(w.Body.Bytes(), &body), ShouldBeNil)
+		return w, body
+	}
+
+	Convey("Given a healthcheck with an OK check that has been run and a WARNING check", t, func() {
+		hc := getTestHealthCheck(t10, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{
+			{name: "mongodb", status: StatusOK, message: "mongodb is ok", lastChecked: &t0, lastSuccess: &t0},
+			{name: "kafka", status: StatusWarning, message: "kafka is slow", lastChecked: &t0, lastFailure: &t0},
+		}, true)
+		hc.Checks[0].metrics.recordRun(250*time.Millisecond, false)
+
+		Convey("When the handler is called asking for the IETF format", func() {
+			w, body := callHandler(&hc)
+
+			Convey("Then the response is in the IETF format with a warn status", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/health+json")
+				So(w.Header().Get("Vary"), ShouldEqual, "Accept")
+				So(body["status"], ShouldEqual, "warn")
+				So(body["version"], ShouldEqual, testVersion.Version)
+				So(body["releaseId"], ShouldEqual, testVersion.GitCommit)
+				So(body, ShouldNotContainKey, "output")
+			})
+
+			Convey("Then each check is keyed by its name and measurement", func() {
+				checks := body["checks"].(map[string]interface{})
+				So(checks, ShouldHaveLength, 2)
+
+				mongodb := checks["mongodb:responseTime"].([]interface{})[0].(map[string]interface{})
+				So(mongodb["componentId"], ShouldEqual, "mongodb")
+				So(mongodb["status"], ShouldEqual, "pass")
+				So(mongodb["observedValue"], ShouldEqual, 250)
+				So(mongodb["observedUnit"], ShouldEqual, "ms")
+				So(mongodb["time"], ShouldEqual, t0.Format(time.RFC3339Nano))
+				So(mongodb, ShouldNotContainKey, "output")
+
+				kafka := checks["kafka:responseTime"].([]interface{})[0].(map[string]interface{})
+				So(kafka["status"], ShouldEqual, "warn")
+				So(kafka["output"], ShouldEqual, "kafka is slow")
+				So(kafka, ShouldNotContainKey, "observedValue")
+			})
+		})
+	})
+
+	Convey("Given a healthcheck with a check that has been CRITICAL for longer than the critical timeout", t, func() {
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{
+			{name: "mongodb", status: StatusCritical, message: "mongodb is down", lastChecked: &t0, lastFailure: &t0, criticalSince: &t20},
+		}, true)
+
+		Convey("When the handler is called asking for the IETF format", func() {
+			w, body := callHandler(&hc)
+
+			Convey("Then the response has a fail status and a 503 status code", func() {
+				So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+				So(body["status"], ShouldEqual, "fail")
+				mongodb := body["checks"].(map[string]interface{})["mongodb:responseTime"].([]interface{})[0].(map[string]interface{})
+				So(mongodb["status"], ShouldEqual, "fail")
+				So(mongodb["output"], ShouldEqual, "mongodb is down")
+			})
+		})
+	})
+
+	Convey("Given a healthcheck with an OK check whose status has been overridden to CRITICAL", t, func() {
+		hc := getTestHealthCheck(t10, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{
+			{name: "mongodb", status: StatusOK, message: "mongodb is ok", lastChecked: &t0, lastSuccess: &t0},
+		}, true)
+		hc.Checks[0].state.setOverride(&Override{Status: StatusCritical, Reason: "known issue"}, nil)
+
+		Convey("When the handler is called asking for the IETF format", func() {
+			_, body := callHandler(&hc)
+
+			Convey("Then the check is reported with the forced status and the reason of the override", func() {
+				mongodb := body["checks"].(map[string]interface{})["mongodb:responseTime"].([]interface{})[0].(map[string]interface{})
+				So(mongodb["status"], ShouldEqual, "fail")
+				So(mongodb["output"], ShouldEqual, "known issue")
+			})
+		})
+	})
+
+	Convey("Given a healthcheck with a check that has not run yet", t, func() {
+		hc := getTestHealthCheck(t0, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{{name: "mongodb"}}, false)
+
+		Convey("When the handler is called asking for the IETF format", func() {
+			w, body := callHandler(&hc)
+
+			Convey("Then the response explains that the app is starting up", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(body["status"], ShouldEqual, "warn")
+				So(body["output"], ShouldEqual, "a dependency is still starting up")
+				mongodb := body["checks"].(map[string]interface{})["mongodb:responseTime"].([]interface{})[0].(map[string]interface{})
+				So(mongodb["status"], ShouldEqual, "warn")
+				So(mongodb["output"], ShouldEqual, "check has not run yet")
+				So(mongodb, ShouldNotContainKey, "time")
+			})
+		})
+	})
+}
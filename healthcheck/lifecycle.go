@@ -0,0 +1,36 @@
+package healthcheck
+
+import "errors"
+
+// lifecycle represents the state of the health check, which can be started and stopped any number of times
+type lifecycle int
+
+// A list of possible lifecycle states
+const (
+	// lifecycleNew is the state of a health check that has not been started yet
+	lifecycleNew lifecycle = iota
+	// lifecycleRunning is the state of a health check that has been started and is running its checks
+	lifecycleRunning
+	// lifecycleStopped is the state of a health check that has been stopped, and may be started again
+	lifecycleStopped
+)
+
+// A list of errors returned for invalid lifecycle transitions
+var (
+	ErrAlreadyRunning = errors.New("health check is already running")
+	ErrNotRunning     = errors.New("health check is not running")
+)
+
+// isRunning returns true if the health check has been started and not stopped since.
+// The caller must hold the statusLock.
+func (hc *HealthCheck) isRunning() bool {
+	return hc.lifecycle == lifecycleRunning
+}
+
+// IsRunning returns true if the health check has been started and not stopped since
+func (hc *HealthCheck) IsRunning() bool {
+	hc.statusLock.RLock()
+	defer hc.statusLock.RUnlock()
+
+	return hc.isRunning()
+}
@@ -0,0 +1,90 @@
+package healthcheck
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// waitForRuns waits for the provided counter to go above the provided number of runs
+func waitForRuns(runs *int32, n int32) {
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(runs) <= n && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	So(atomic.LoadInt32(runs), ShouldBeGreaterThan, n)
+}
+
+func TestLifecycle(t *testing.T) {
+	Convey("Given a new Health Check with a check", t, func() {
+		var runs int32
+		checkerFunc := func(ctx context.Context, state *CheckState) error {
+			atomic.AddInt32(&runs, 1)
+			return state.Update(StatusOK, "ok", 0)
+		}
+
+		hc := New(version, criticalTimeout, 10*time.Millisecond)
+		So(hc.AddCheck("check", checkerFunc), ShouldBeNil)
+		So(hc.IsRunning(), ShouldBeFalse)
+
+		Convey("Then it cannot be stopped before it has been started", func() {
+			So(hc.Stop(), ShouldEqual, ErrNotRunning)
+		})
+
+		Convey("When it is started", func() {
+			So(hc.Start(context.Background()), ShouldBeNil)
+			waitForRuns(&runs, 0)
+
+			Convey("Then it is running, and cannot be started again", func() {
+				So(hc.IsRunning(), ShouldBeTrue)
+				So(hc.Start(context.Background()), ShouldEqual, ErrAlreadyRunning)
+				So(hc.Stop(), ShouldBeNil)
+			})
+
+			Convey("Then it can be stopped only once", func() {
+				So(hc.Stop(), ShouldBeNil)
+				So(hc.IsRunning(), ShouldBeFalse)
+				So(hc.Stop(), ShouldEqual, ErrNotRunning)
+			})
+
+			Convey("Then it can be stopped and started again, running its checks again", func() {
+				So(hc.Stop(), ShouldBeNil)
+				stopped := atomic.LoadInt32(&runs)
+
+				So(hc.AddCheck("check added while stopped", checkerFunc), ShouldBeNil)
+				time.Sleep(30 * time.Millisecond)
+				So(atomic.LoadInt32(&runs), ShouldEqual, stopped)
+
+				So(hc.Start(context.Background()), ShouldBeNil)
+				waitForRuns(&runs, stopped+2)
+				So(hc.Stop(), ShouldBeNil)
+			})
+		})
+
+		Convey("When it is started with a context that is then cancelled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			So(hc.Start(ctx), ShouldBeNil)
+			waitForRuns(&runs, 0)
+			cancel()
+
+			Convey("Then it can still be stopped without blocking, and started again", func() {
+				stopped := make(chan error)
+				go func() {
+					stopped <- hc.Stop()
+				}()
+				select {
+				case err := <-stopped:
+					So(err, ShouldBeNil)
+				case <-time.After(time.Second):
+					So("Stop did not return", ShouldBeEmpty)
+				}
+
+				So(hc.Start(context.Background()), ShouldBeNil)
+				So(hc.Stop(), ShouldBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,248 @@
+package healthcheck
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// metricsContentType is the content type of the Prometheus text exposition format
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// durationBuckets are the upper bounds, in seconds, of the check duration histogram buckets
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// statuses are all the possible statuses, in the order in which they are exported
+var statuses = []string{StatusOK, StatusWarning, StatusCritical}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// checkMetrics keeps track of the runs of a check
+type checkMetrics struct {
+	mutex        *sync.Mutex
+	bucketCounts []uint64
+	durationSum  float64
+	runs         uint64
+	failures     uint64
+	lastDuration time.Duration
+}
+
+// newCheckMetrics returns a pointer to a new instantiated checkMetrics
+func newCheckMetrics() *checkMetrics {
+	return &checkMetrics{
+		mutex:        &sync.Mutex{},
+		bucketCounts: make([]uint64, len(durationBuckets)),
+	}
+}
+
+// recordRun records the duration of a run of the check and whether it failed
+func (m *checkMetrics) recordRun(duration time.Duration, failed bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	seconds := duration.Seconds()
+	for i, bucket := range durationBuckets {
+		if seconds <= bucket {
+			m.bucketCounts[i]++
+		}
+	}
+	m.durationSum += seconds
+	m.runs++
+	if failed {
+		m.failures++
+	}
+	m.lastDuration = duration
+}
+
+// snapshot returns a copy of the metrics that is safe to read without holding the mutex
+func (m *checkMetrics) snapshot() checkMetrics {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	s := *m
+	s.mutex = nil
+	s.bucketCounts = append([]uint64{}, m.bucketCounts...)
+	return s
+}
+
+// metricsWriter writes metrics in the Prometheus text exposition format
+type metricsWriter struct {
+	bytes.Buffer
+}
+
+// header writes the help and type lines of a metric
+func (w *metricsWriter) header(name, metricType, help string) {
+	w.WriteString("# HELP " + name + " " + help + "\n")
+	w.WriteString("# TYPE " + name + " " + metricType + "\n")
+}
+
+// sample writes a single sample of a metric, with labels provided as name/value pairs
+func (w *metricsWriter) sample(name string, value float64, labels ...string) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labels[i] + `="` + labelValueEscaper.Replace(labels[i+1]) + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+// MetricsHandler responds to an http request with the current app and check states in the Prometheus text exposition format
+func (hc *HealthCheck) MetricsHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	hc.statusLock.Lock()
+	appStatus := hc.updateStatus(ctx)
+	uptime := hc.Uptime
+	checks := append([]*Check{}, hc.Checks...)
+	hc.statusLock.Unlock()
+
+	now := hc.getClock().Now().UTC()
+	names := getMetricCheckNames(checks)
+	mw := &metricsWriter{}
+
+	mw.header("healthcheck_status", "gauge", "Overall status of the app, 1 for the current status and 0 otherwise.")
+	for _, status := range statuses {
+		mw.sample("healthcheck_status", boolToFloat(appStatus == status), "status", status)
+	}
+
+	mw.header("healthcheck_uptime_seconds", "gauge", "Time since the health check was started.")
+	mw.sample("healthcheck_uptime_seconds", (uptime * time.Millisecond).Seconds())
+
+	mw.header("healthcheck_check_status", "gauge", "Status of the check, 1 for the current status and 0 otherwise.")
+	for i, check := range checks {
+		checkStatus := check.state.Status()
+		for _, status := range statuses {
+			mw.sample("healthcheck_check_status", boolToFloat(checkStatus == status), "check", names[i], "status", status)
+		}
+	}
+
+	mw.header("healthcheck_check_last_checked_age_seconds", "gauge", "Time since the check was last run.")
+	for i, check := range checks {
+		if lastChecked := check.state.LastChecked(); lastChecked != nil {
+			mw.sample("healthcheck_check_last_checked_age_seconds", now.Sub(*lastChecked).Seconds(), "check", names[i])
+		}
+	}
+
+	mw.header("healthcheck_check_last_success_timestamp_seconds", "gauge", "Unix time of the last successful check.")
+	for i, check := range checks {
+		if lastSuccess := check.state.LastSuccess(); lastSuccess != nil {
+			mw.sample("healthcheck_check_last_success_timestamp_seconds", unixSeconds(*lastSuccess), "check", names[i])
+		}
+	}
+
+	mw.header("healthcheck_check_last_failure_timestamp_seconds", "gauge", "Unix time of the last failed check.")
+	for i, check := range checks {
+		if lastFailure := check.state.LastFailure(); lastFailure != nil {
+			mw.sample("healthcheck_check_last_failure_timestamp_seconds", unixSeconds(*lastFailure), "check", names[i])
+		}
+	}
+
+	snapshots := make([]checkMetrics, len(checks))
+	for i, check := range checks {
+		if check.metrics != nil {
+			snapshots[i] = check.metrics.snapshot()
+		}
+	}
+
+	mw.header("healthcheck_check_duration_seconds", "histogram", "Duration of the check runs.")
+	for i := range checks {
+		if snapshots[i].bucketCounts == nil {
+			continue
+		}
+		name := names[i]
+		for j, bucket := range durationBuckets {
+			mw.sample("healthcheck_check_duration_seconds_bucket", float64(snapshots[i].bucketCounts[j]), "check", name, "le", strconv.FormatFloat(bucket, 'g', -1, 64))
+		}
+		mw.sample("healthcheck_check_duration_seconds_bucket", float64(snapshots[i].runs), "check", name, "le", "+Inf")
+		mw.sample("healthcheck_check_duration_seconds_sum", snapshots[i].durationSum, "check", name)
+		mw.sample("healthcheck_check_duration_seconds_count", float64(snapshots[i].runs), "check", name)
+	}
+
+	mw.header("healthcheck_check_runs_total", "counter", "Number of times the check has been run.")
+	for i := range checks {
+		if snapshots[i].bucketCounts != nil {
+			mw.sample("healthcheck_check_runs_total", float64(snapshots[i].runs), "check", names[i])
+		}
+	}
+
+	mw.header("healthcheck_check_failures_total", "counter", "Number of check runs that failed or resulted in a WARNING or CRITICAL state.")
+	for i := range checks {
+		if snapshots[i].bucketCounts != nil {
+			mw.sample("healthcheck_check_failures_total", float64(snapshots[i].failures), "check", names[i])
+		}
+	}
+
+	counters, subscribers, stuck := hc.getNotificationCounters()
+
+	mw.header("healthcheck_subscribers", "gauge", "Number of subscribers to health updates.")
+	mw.sample("healthcheck_subscribers", float64(subscribers))
+
+	mw.header("healthcheck_subscribers_stuck", "gauge", "Number of subscribers that have been handling a health update for longer than the stuck subscriber timeout.")
+	mw.sample("healthcheck_subscribers_stuck", float64(stuck))
+
+	mw.header("healthcheck_subscriber_notifications_delivered_total", "counter", "Number of health updates handled by subscribers.")
+	mw.sample("healthcheck_subscriber_notifications_delivered_total", float64(counters.delivered))
+
+	mw.header("healthcheck_subscriber_notifications_coalesced_total", "counter", "Number of health updates replaced by a later one before the subscriber could handle them.")
+	mw.sample("healthcheck_subscriber_notifications_coalesced_total", float64(counters.coalesced))
+
+	mw.header("healthcheck_subscriber_notifications_dropped_total", "counter", "Number of health updates dropped because the subscriber was unsubscribed before handling them.")
+	mw.sample("healthcheck_subscriber_notifications_dropped_total", float64(counters.dropped))
+
+	mw.header("healthcheck_watch_events_dropped_total", "counter", "Number of watch events dropped because the queue of their watch was full.")
+	mw.sample("healthcheck_watch_events_dropped_total", float64(hc.getDroppedWatchEvents()))
+
+	w.Header().Set("Content-Type", metricsContentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(mw.Bytes()); err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err)
+		return
+	}
+}
+
+// getMetricCheckNames returns the values of the check label of the provided checks. A check that has the same name as
+// a previous one is suffixed with a number that is not used by any other check, e.g. "mongodb#2", so that every series is unique.
+func getMetricCheckNames(checks []*Check) []string {
+	names := make([]string, len(checks))
+	used := map[string]bool{}
+	for i, check := range checks {
+		names[i] = check.state.Name()
+		used[names[i]] = true
+	}
+
+	seen := map[string]bool{}
+	for i, name := range names {
+		if seen[name] {
+			for n := 2; used[names[i]]; n++ {
+				names[i] = name + "#" + strconv.Itoa(n)
+			}
+			used[names[i]] = true
+		}
+		seen[name] = true
+	}
+	return names
+}
+
+// boolToFloat returns 1 for true and 0 for false
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// unixSeconds returns the provided time as fractional seconds since the unix epoch
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
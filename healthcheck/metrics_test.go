@@ -0,0 +1,113 @@
+package healthcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheckMetrics(t *testing.T) {
+	Convey("Given new check metrics", t, func() {
+		m := newCheckMetrics()
+
+		Convey("When runs are recorded", func() {
+			m.recordRun(20*time.Millisecond, false)
+			m.recordRun(2*time.Second, true)
+
+			Convey("Then the histogram buckets are cumulative", func() {
+				s := m.snapshot()
+				So(s.bucketCounts[0], ShouldEqual, 0) // 5ms
+				So(s.bucketCounts[2], ShouldEqual, 1) // 25ms
+				So(s.bucketCounts[7], ShouldEqual, 1) // 1s
+				So(s.bucketCounts[8], ShouldEqual, 2) // 2.5s
+				So(s.durationSum, ShouldAlmostEqual, 2.02)
+				So(s.runs, ShouldEqual, 2)
+				So(s.failures, ShouldEqual, 1)
+				So(s.lastDuration, ShouldEqual, 2*time.Second)
+			})
+		})
+	})
+}
+
+func TestMetricsHandler(t *testing.T) {
+	t0 := time.Now().UTC()
+	t10 := t0.Add(-10 * time.Minute) // 10 min ago
+
+	Convey("Given a healthcheck with an OK check that has been run and a WARNING check", t, func() {
+		hc := getTestHealthCheck(t10, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{
+			{name: "mongodb", status: StatusOK, lastChecked: &t0, lastSuccess: &t0, lastFailure: &t10},
+			{name: `kafka "producer"`, status: StatusWarning, lastChecked: &t0, lastFailure: &t0},
+		}, true)
+		hc.Checks[0].metrics.recordRun(30*time.Millisecond, false)
+
+		Convey("When the metrics handler is called", func() {
+			w := httptest.NewRecorder()
+			hc.MetricsHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			body := w.Body.String()
+
+			Convey("Then the response uses the text exposition format", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "text/plain; version=0.0.4; charset=utf-8")
+				So(body, ShouldContainSubstring, "# TYPE healthcheck_status gauge\n")
+				So(body, ShouldContainSubstring, "# TYPE healthcheck_check_duration_seconds histogram\n")
+			})
+
+			Convey("Then the overall app status is exported", func() {
+				So(body, ShouldContainSubstring, "healthcheck_status{status=\"OK\"} 0\n")
+				So(body, ShouldContainSubstring, "healthcheck_status{status=\"WARNING\"} 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_status{status=\"CRITICAL\"} 0\n")
+			})
+
+			Convey("Then the status of each check is exported with escaped label values", func() {
+				So(body, ShouldContainSubstring, "healthcheck_check_status{check=\"mongodb\",status=\"OK\"} 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_status{check=\"mongodb\",status=\"WARNING\"} 0\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_status{check=\"kafka \\\"producer\\\"\",status=\"WARNING\"} 1\n")
+			})
+
+			Convey("Then the check timestamps are exported", func() {
+				So(body, ShouldContainSubstring, "healthcheck_check_last_checked_age_seconds{check=\"mongodb\"} ")
+				So(body, ShouldContainSubstring, "healthcheck_check_last_success_timestamp_seconds{check=\"mongodb\"} "+strconv.FormatFloat(unixSeconds(t0), 'g', -1, 64)+"\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_last_failure_timestamp_seconds{check=\"mongodb\"} "+strconv.FormatFloat(unixSeconds(t10), 'g', -1, 64)+"\n")
+				So(body, ShouldNotContainSubstring, "healthcheck_check_last_success_timestamp_seconds{check=\"kafka")
+			})
+
+			Convey("Then the check durations and counters are exported", func() {
+				So(body, ShouldContainSubstring, "healthcheck_check_duration_seconds_bucket{check=\"mongodb\",le=\"0.025\"} 0\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_duration_seconds_bucket{check=\"mongodb\",le=\"0.05\"} 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_duration_seconds_bucket{check=\"mongodb\",le=\"+Inf\"} 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_duration_seconds_count{check=\"mongodb\"} 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_runs_total{check=\"mongodb\"} 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_failures_total{check=\"mongodb\"} 0\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_failures_total{check=\"kafka \\\"producer\\\"\"} 0\n")
+			})
+		})
+	})
+
+	Convey("Given a healthcheck with checks that have the same name", t, func() {
+		hc := getTestHealthCheck(t10, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{
+			{name: "mongodb", status: StatusOK, lastChecked: &t0, lastSuccess: &t0},
+			{name: "mongodb", status: StatusWarning, lastChecked: &t0, lastFailure: &t0},
+			{name: "mongodb#2", status: StatusOK, lastChecked: &t0, lastSuccess: &t0},
+		}, true)
+
+		Convey("When the metrics handler is called", func() {
+			w := httptest.NewRecorder()
+			hc.MetricsHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			body := w.Body.String()
+
+			Convey("Then every check is exported with a unique check label", func() {
+				So(body, ShouldContainSubstring, "healthcheck_check_status{check=\"mongodb\",status=\"OK\"} 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_status{check=\"mongodb#3\",status=\"WARNING\"} 1\n")
+				So(body, ShouldContainSubstring, "healthcheck_check_status{check=\"mongodb#2\",status=\"OK\"} 1\n")
+				So(strings.Count(body, "healthcheck_check_runs_total{check=\"mongodb\"}"), ShouldEqual, 1)
+			})
+		})
+	})
+}
@@ -0,0 +1,327 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Override represents a status that has been forced by an operator, e.g. to drain an instance during an incident
+type Override struct {
+	Status  string     `json:"status"`
+	Reason  string     `json:"reason"`
+	SetAt   time.Time  `json:"set_at"`
+	Expires *time.Time `json:"expires,omitempty"`
+	timer   Timer
+}
+
+// overrideRequest represents the body of a request to the override handler to set an override
+type overrideRequest struct {
+	Check  string `json:"check,omitempty"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+	TTL    string `json:"ttl,omitempty"`
+}
+
+// overrideResponse represents the body returned by the override handler
+type overrideResponse struct {
+	App    *Override            `json:"app,omitempty"`
+	Checks map[string]*Override `json:"checks"`
+}
+
+// newOverride returns a pointer to a new instantiated Override set at the provided time,
+// that expires after the provided ttl, or never if it is 0
+func newOverride(now time.Time, status, reason string, ttl time.Duration) (*Override, error) {
+	if !isValidStatus(status) {
+		return nil, fmt.Errorf("invalid override status, must be one of %s, %s or %s", StatusOK, StatusWarning, StatusCritical)
+	}
+	if ttl < 0 {
+		return nil, errors.New("invalid override ttl, must not be negative")
+	}
+
+	now = now.UTC()
+	override := &Override{
+		Status: status,
+		Reason: reason,
+		SetAt:  now,
+	}
+	if ttl > 0 {
+		expires := now.Add(ttl)
+		override.Expires = &expires
+	}
+	return override, nil
+}
+
+// isValidStatus returns true if the provided status is one of the possible check statuses
+func isValidStatus(status string) bool {
+	switch status {
+	case StatusOK, StatusWarning, StatusCritical:
+		return true
+	}
+	return false
+}
+
+// stopTimer stops the expiry timer of the override, if it has one
+func (o *Override) stopTimer() {
+	if o != nil && o.timer != nil {
+		o.timer.Stop()
+	}
+}
+
+// SetOverride forces the status of the app, regardless of the state of its checks, until it is cleared with ClearOverride
+// or the provided ttl expires. A ttl of 0 means that the override never expires.
+// The override is reported in the health check response with its reason, and the subscribers are notified.
+// The status of every subscriber and watch is forced too, as well as the readiness probe.
+func (hc *HealthCheck) SetOverride(status, reason string, ttl time.Duration) error {
+	override, err := newOverride(hc.getClock().Now(), status, reason, ttl)
+	if err != nil {
+		return err
+	}
+
+	hc.statusLock.Lock()
+	hc.Override.stopTimer()
+	hc.Override = override
+	if ttl > 0 {
+		override.timer = hc.getClock().AfterFunc(ttl, func() {
+			hc.clearOverride(override)
+		})
+	}
+	hc.statusLock.Unlock()
+
+	hc.healthChangeCallback()
+	return nil
+}
+
+// ClearOverride stops forcing the status of the app, so that it is determined by the state of its checks again
+func (hc *HealthCheck) ClearOverride() {
+	hc.statusLock.Lock()
+	override := hc.Override
+	hc.statusLock.Unlock()
+
+	if override != nil {
+		hc.clearOverride(override)
+	}
+}
+
+// clearOverride clears the override of the app, if it is still the provided one, and notifies the subscribers
+func (hc *HealthCheck) clearOverride(override *Override) {
+	hc.statusLock.Lock()
+	if hc.Override != override {
+		hc.statusLock.Unlock()
+		return
+	}
+	override.stopTimer()
+	hc.Override = nil
+	hc.statusLock.Unlock()
+
+	hc.healthChangeCallback()
+}
+
+// SetCheckOverride forces the status of every check with the provided name, regardless of the result of its checker,
+// until it is cleared with ClearCheckOverride or the provided ttl expires. A ttl of 0 means that the override never expires.
+// The override is reported with the check in the health check response, and the subscribers to the check are notified.
+func (hc *HealthCheck) SetCheckOverride(name, status, reason string, ttl time.Duration) error {
+	checks := hc.getChecksByName(name)
+	if len(checks) == 0 {
+		return fmt.Errorf("check not found: %s", name)
+	}
+
+	for _, check := range checks {
+		override, err := newOverride(hc.getClock().Now(), status, reason, ttl)
+		if err != nil {
+			return err
+		}
+		check.state.setOverride(override, func() {
+			hc.clearCheckOverride(check, override)
+		})
+		hc.checkChangeCallback(check)()
+	}
+	return nil
+}
+
+// ClearCheckOverride stops forcing the status of every check with the provided name,
+// so that it is determined by the result of its checker again
+func (hc *HealthCheck) ClearCheckOverride(name string) error {
+	checks := hc.getChecksByName(name)
+	if len(checks) == 0 {
+		return fmt.Errorf("check not found: %s", name)
+	}
+
+	for _, check := range checks {
+		if override := check.state.Override(); override != nil {
+			hc.clearCheckOverride(check, override)
+		}
+	}
+	return nil
+}
+
+// clearCheckOverride clears the override of the check, if it is still the provided one, and notifies the subscribers
+func (hc *HealthCheck) clearCheckOverride(check *Check, override *Override) {
+	if !check.state.clearOverride(override) {
+		return
+	}
+	hc.checkChangeCallback(check)()
+}
+
+// getChecksByName returns all the checks with the provided name
+func (hc *HealthCheck) getChecksByName(name string) []*Check {
+	hc.statusLock.RLock()
+	defer hc.statusLock.RUnlock()
+
+	checks := []*Check{}
+	for _, check := range hc.Checks {
+		if check.state.Name() == name {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// Override gets the status forced on the check by an operator, or nil if there is none
+func (s *CheckState) Override() *Override {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.override
+}
+
+// reportedStatus gets the status forced on the check by an operator if there is one, or the status of the check otherwise
+func (s *CheckState) reportedStatus() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.override != nil {
+		return s.override.Status
+	}
+	return s.status
+}
+
+// setOverride sets the status forced on the check, replacing any previous override.
+// If the override expires, the provided function is called once it does.
+func (s *CheckState) setOverride(override *Override, onExpire func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.override.stopTimer()
+	s.override = override
+	if override.Expires != nil {
+		override.timer = s.getClock().AfterFunc(override.Expires.Sub(override.SetAt), onExpire)
+	}
+}
+
+// clearOverride clears the status forced on the check if it is still the provided one, and returns true if it was cleared
+func (s *CheckState) clearOverride(override *Override) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.override != override {
+		return false
+	}
+	s.override.stopTimer()
+	s.override = nil
+	return true
+}
+
+// OverrideHandler is an admin handler to set and clear overrides of the app status, or of the status of a check.
+// GET responds with the current overrides. PUT sets an override from a json body with the status, the reason,
+// an optional ttl as a duration string (e.g. "30m") and an optional check name. DELETE clears the override
+// of the app, or of the check provided in the 'check' query parameter.
+// The handler must be protected in the same way as any other admin endpoint of the app.
+func (hc *HealthCheck) OverrideHandler(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		body := overrideRequest{}
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		var ttl time.Duration
+		if body.TTL != "" {
+			var err error
+			if ttl, err = time.ParseDuration(body.TTL); err != nil {
+				http.Error(w, "invalid ttl: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if !isValidStatus(body.Status) || ttl < 0 {
+			http.Error(w, fmt.Sprintf("invalid override, status must be one of %s, %s or %s and ttl must not be negative", StatusOK, StatusWarning, StatusCritical), http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		if body.Check == "" {
+			err = hc.SetOverride(body.Status, body.Reason, ttl)
+		} else {
+			err = hc.SetCheckOverride(body.Check, body.Status, body.Reason, ttl)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Warn(ctx, "health status override set", log.Data{"check": body.Check, "status": body.Status, "reason": body.Reason, "ttl": body.TTL})
+	case http.MethodDelete:
+		if check := req.URL.Query().Get("check"); check != "" {
+			if err := hc.ClearCheckOverride(check); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+		} else {
+			hc.ClearOverride()
+		}
+		log.Warn(ctx, "health status override cleared", log.Data{"check": req.URL.Query().Get("check")})
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, err := json.Marshal(hc.getOverrides())
+	if err != nil {
+		log.Error(ctx, "failed to marshal json", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(b); err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err)
+		return
+	}
+}
+
+// getOverrides returns the overrides of the app and of every check that has one
+func (hc *HealthCheck) getOverrides() overrideResponse {
+	hc.statusLock.RLock()
+	defer hc.statusLock.RUnlock()
+
+	resp := overrideResponse{
+		App:    hc.Override,
+		Checks: map[string]*Override{},
+	}
+	for _, check := range hc.Checks {
+		if override := check.state.Override(); override != nil {
+			resp.Checks[check.state.Name()] = override
+		}
+	}
+	return resp
+}
+
+// getForcedStatus returns the status of the app if it is forced, because the app is draining or has an override.
+// The caller must hold the statusLock.
+func (hc *HealthCheck) getForcedStatus() (string, bool) {
+	if hc.Draining {
+		return StatusCritical, true
+	}
+	if hc.Override != nil {
+		return hc.Override.Status, true
+	}
+	return "", false
+}
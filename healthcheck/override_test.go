@@ -0,0 +1,259 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck/mock"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// notifiedStatuses returns a subscriber that records every status it is notified of
+func notifiedStatuses() (*mock.SubscriberMock, func() []string) {
+	var mutex sync.Mutex
+	statuses := []string{}
+	s := &mock.SubscriberMock{
+		OnHealthUpdateFunc: func(status string) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			statuses = append(statuses, status)
+		},
+	}
+	return s, func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string{}, statuses...)
+	}
+}
+
+// waitForNotifications waits for the provided number of notifications to be recorded
+func waitForNotifications(statuses func() []string, n int) []string {
+	deadline := time.Now().Add(time.Second)
+	for len(statuses()) < n && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return statuses()
+}
+
+// waitForAppStatus waits for the app status to become the expected one
+func waitForAppStatus(hc *HealthCheck, expected string) {
+	deadline := time.Now().Add(time.Second)
+	for hc.GetChecksStatus() != expected && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	So(hc.GetChecksStatus(), ShouldEqual, expected)
+}
+
+func TestOverride(t *testing.T) {
+	checkerFunc := func(ctx context.Context, state *CheckState) error {
+		return nil
+	}
+
+	Convey("Given a Health Check with 2 checks that are OK and a subscriber", t, func() {
+		hc := New(version, criticalTimeout, interval)
+		c1, err := hc.AddAndGetCheck("check 1", checkerFunc)
+		So(err, ShouldBeNil)
+		c2, err := hc.AddAndGetCheck("check 2", checkerFunc)
+		So(err, ShouldBeNil)
+		So(c1.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+		So(c2.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+
+		s, statuses := notifiedStatuses()
+		hc.Subscribe(s, c1)
+
+		Convey("When the app status is overridden", func() {
+			So(hc.SetOverride(StatusCritical, "draining for an incident", 0), ShouldBeNil)
+
+			Convey("Then the app status is forced, and the subscriber is notified", func() {
+				So(hc.GetChecksStatus(), ShouldEqual, StatusCritical)
+				So(hc.GetChecksStatus(c1), ShouldEqual, StatusCritical)
+				So(waitForNotifications(statuses, 1), ShouldResemble, []string{StatusCritical})
+			})
+
+			Convey("Then the status is not recalculated by a state change or a request", func() {
+				So(c1.state.Update(StatusWarning, "slow", 0), ShouldBeNil)
+
+				w := httptest.NewRecorder()
+				hc.Handler(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+
+				resp := map[string]interface{}{}
+				So(json.Unmarshal(w.Body.Bytes(), &resp), ShouldBeNil)
+				So(resp["status"], ShouldEqual, StatusCritical)
+				So(resp["override"].(map[string]interface{})["reason"], ShouldEqual, "draining for an incident")
+			})
+
+			Convey("Then the readiness probe fails", func() {
+				w := httptest.NewRecorder()
+				hc.ReadinessHandler(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+				So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+			})
+
+			Convey("Then the status is recalculated once the override is cleared", func() {
+				hc.ClearOverride()
+				So(hc.GetChecksStatus(), ShouldEqual, StatusOK)
+				So(hc.Override, ShouldBeNil)
+			})
+		})
+
+		Convey("When the app status is overridden with a ttl", func() {
+			So(hc.SetOverride(StatusWarning, "maintenance", 20*time.Millisecond), ShouldBeNil)
+			So(hc.Override.Expires, ShouldNotBeNil)
+
+			Convey("Then the override is cleared once it expires", func() {
+				waitForAppStatus(&hc, StatusOK)
+			})
+		})
+
+		Convey("When the app status is overridden with an invalid status", func() {
+			err := hc.SetOverride("DRAINING", "", 0)
+
+			Convey("Then an error is returned and the status is not forced", func() {
+				So(err, ShouldNotBeNil)
+				So(hc.Override, ShouldBeNil)
+			})
+		})
+
+		Convey("When the status of a check is overridden", func() {
+			So(hc.SetCheckOverride("check 1", StatusWarning, "known issue", 0), ShouldBeNil)
+
+			Convey("Then the check status is forced, and reported with the check", func() {
+				So(hc.GetChecksStatus(c1), ShouldEqual, StatusWarning)
+				So(hc.GetChecksStatus(c2), ShouldEqual, StatusOK)
+				So(waitForNotifications(statuses, 1), ShouldResemble, []string{StatusWarning})
+
+				b, err := json.Marshal(c1)
+				So(err, ShouldBeNil)
+				So(string(b), ShouldContainSubstring, `"override":{"status":"WARNING","reason":"known issue"`)
+			})
+
+			Convey("Then the result of the checker does not change it", func() {
+				So(c1.state.Update(StatusCritical, "down", 0), ShouldBeNil)
+				So(hc.GetChecksStatus(c1), ShouldEqual, StatusWarning)
+			})
+
+			Convey("Then the forced status is reported as the status of the check", func() {
+				So(c1.state.Update(StatusCritical, "down", 0), ShouldBeNil)
+				b, err := json.Marshal(c1)
+				So(err, ShouldBeNil)
+
+				var body map[string]interface{}
+				So(json.Unmarshal(b, &body), ShouldBeNil)
+				So(body["status"], ShouldEqual, StatusWarning)
+				So(body["message"], ShouldEqual, "down")
+			})
+
+			Convey("Then the check status is recalculated once the override is cleared", func() {
+				So(hc.ClearCheckOverride("check 1"), ShouldBeNil)
+				So(hc.GetChecksStatus(c1), ShouldEqual, StatusOK)
+				So(c1.state.Override(), ShouldBeNil)
+			})
+		})
+
+		Convey("When the status of a check is overridden with a ttl", func() {
+			So(hc.SetCheckOverride("check 2", StatusCritical, "testing", 20*time.Millisecond), ShouldBeNil)
+
+			Convey("Then the override is cleared once it expires", func() {
+				waitForAppStatus(&hc, StatusOK)
+				So(c2.state.Override(), ShouldBeNil)
+			})
+		})
+
+		Convey("When the status of an unknown check is overridden", func() {
+			err := hc.SetCheckOverride("unknown", StatusCritical, "", 0)
+
+			Convey("Then a check not found error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "check not found: unknown")
+			})
+		})
+	})
+}
+
+func TestOverrideHandler(t *testing.T) {
+	checkerFunc := func(ctx context.Context, state *CheckState) error {
+		return nil
+	}
+
+	Convey("Given a Health Check with a check that is OK", t, func() {
+		hc := New(version, criticalTimeout, interval)
+		c, err := hc.AddAndGetCheck("mongodb", checkerFunc)
+		So(err, ShouldBeNil)
+		So(c.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+
+		Convey("When an override of the app status is put", func() {
+			w := httptest.NewRecorder()
+			body := `{"status":"CRITICAL","reason":"draining","ttl":"1h"}`
+			hc.OverrideHandler(w, httptest.NewRequest(http.MethodPut, "/health/override", strings.NewReader(body)))
+
+			Convey("Then the app status is forced and the current overrides are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(hc.GetChecksStatus(), ShouldEqual, StatusCritical)
+
+				resp := overrideResponse{}
+				So(json.Unmarshal(w.Body.Bytes(), &resp), ShouldBeNil)
+				So(resp.App.Status, ShouldEqual, StatusCritical)
+				So(resp.App.Reason, ShouldEqual, "draining")
+				So(resp.App.Expires, ShouldNotBeNil)
+				So(resp.Checks, ShouldBeEmpty)
+			})
+
+			Convey("Then the override is cleared by a delete", func() {
+				w := httptest.NewRecorder()
+				hc.OverrideHandler(w, httptest.NewRequest(http.MethodDelete, "/health/override", nil))
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(hc.GetChecksStatus(), ShouldEqual, StatusOK)
+			})
+		})
+
+		Convey("When an override of a check status is put and then deleted", func() {
+			w := httptest.NewRecorder()
+			body := `{"check":"mongodb","status":"WARNING","reason":"failover"}`
+			hc.OverrideHandler(w, httptest.NewRequest(http.MethodPut, "/health/override", strings.NewReader(body)))
+			So(w.Code, ShouldEqual, http.StatusOK)
+
+			resp := overrideResponse{}
+			So(json.Unmarshal(w.Body.Bytes(), &resp), ShouldBeNil)
+			So(resp.App, ShouldBeNil)
+			So(resp.Checks["mongodb"].Status, ShouldEqual, StatusWarning)
+
+			w = httptest.NewRecorder()
+			hc.OverrideHandler(w, httptest.NewRequest(http.MethodDelete, "/health/override?check=mongodb", nil))
+
+			Convey("Then the check status is forced until the override is deleted", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(c.state.Override(), ShouldBeNil)
+			})
+		})
+
+		Convey("When invalid requests are made", func() {
+			for _, tc := range []struct {
+				method string
+				target string
+				body   string
+				code   int
+			}{
+				{http.MethodPut, "/health/override", `{"status":"DRAINING"}`, http.StatusBadRequest},
+				{http.MethodPut, "/health/override", `{"status":"OK","ttl":"soon"}`, http.StatusBadRequest},
+				{http.MethodPut, "/health/override", `not json`, http.StatusBadRequest},
+				{http.MethodPut, "/health/override", `{"check":"unknown","status":"OK"}`, http.StatusNotFound},
+				{http.MethodDelete, "/health/override?check=unknown", "", http.StatusNotFound},
+				{http.MethodPost, "/health/override", "", http.StatusMethodNotAllowed},
+			} {
+				w := httptest.NewRecorder()
+				hc.OverrideHandler(w, httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body)))
+
+				Convey("Then "+tc.method+" "+tc.target+" "+tc.body+" is rejected", func() {
+					So(w.Code, ShouldEqual, tc.code)
+					So(hc.Override, ShouldBeNil)
+				})
+			}
+		})
+	})
+}
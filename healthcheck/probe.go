@@ -0,0 +1,135 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Probe represents a Kubernetes probe that a check can take part in
+type Probe uint8
+
+// A list of possible probes, which may be combined
+const (
+	ProbeLiveness Probe = 1 << iota
+	ProbeReadiness
+	ProbeStartup
+)
+
+// defaultProbes are the probes a check takes part in if none are provided.
+// Checks are excluded from liveness by default, so that an unhealthy dependency does not cause the app to be restarted.
+const defaultProbes = ProbeReadiness | ProbeStartup
+
+// probeResponse represents the body returned by the probe handlers
+type probeResponse struct {
+	Status string   `json:"status"`
+	Checks []*Check `json:"checks"`
+}
+
+// WithProbes sets the probes that the check takes part in, replacing the default of readiness and startup
+func WithProbes(probes ...Probe) CheckOption {
+	return func(c *Check) {
+		c.probes = 0
+		for _, probe := range probes {
+			c.probes |= probe
+		}
+	}
+}
+
+// inProbe returns true if the check takes part in the provided probe
+func (c *Check) inProbe(probe Probe) bool {
+	probes := c.probes
+	if probes == 0 {
+		probes = defaultProbes
+	}
+	return probes&probe != 0
+}
+
+// LivenessHandler responds to a Kubernetes liveness probe.
+// It responds with 503 only if any check taking part in liveness is CRITICAL, otherwise it responds with 200.
+// Checks that have not run yet are ignored, as the startup probe covers the app starting up.
+func (hc *HealthCheck) LivenessHandler(w http.ResponseWriter, req *http.Request) {
+	hc.probeHandler(w, req, ProbeLiveness)
+}
+
+// ReadinessHandler responds to a Kubernetes readiness probe.
+// It responds with 503 while any check taking part in readiness has not run yet, or if their combined status is CRITICAL,
+// otherwise it responds with 200. If the app status has been forced with SetOverride, or the app is draining, it is used instead.
+func (hc *HealthCheck) ReadinessHandler(w http.ResponseWriter, req *http.Request) {
+	hc.probeHandler(w, req, ProbeReadiness)
+}
+
+// StartupHandler responds to a Kubernetes startup probe.
+// It responds with 503 until every check taking part in startup has run at least once, then it responds with 200.
+func (hc *HealthCheck) StartupHandler(w http.ResponseWriter, req *http.Request) {
+	hc.probeHandler(w, req, ProbeStartup)
+}
+
+// probeHandler writes the status of the checks that take part in the provided probe
+func (hc *HealthCheck) probeHandler(w http.ResponseWriter, req *http.Request, probe Probe) {
+	ctx := req.Context()
+
+	hc.statusLock.Lock()
+	checks := hc.getProbeChecks(probe)
+	status, passed := hc.getProbeStatus(probe, checks)
+	b, err := json.Marshal(probeResponse{Status: status, Checks: checks})
+	hc.statusLock.Unlock()
+
+	if err != nil {
+		log.Error(ctx, "failed to marshal json", err, log.Data{"probe": probe})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if passed {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if _, err = w.Write(b); err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err)
+		return
+	}
+}
+
+// getProbeChecks returns the checks that take part in the provided probe
+func (hc *HealthCheck) getProbeChecks(probe Probe) []*Check {
+	checks := []*Check{}
+	for _, check := range hc.Checks {
+		if check.inProbe(probe) {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// getProbeStatus returns the combined status of the provided checks and whether the probe has passed
+func (hc *HealthCheck) getProbeStatus(probe Probe, checks []*Check) (status string, passed bool) {
+	switch probe {
+	case ProbeLiveness:
+		hasRun := []*Check{}
+		for _, check := range checks {
+			if check.hasRun() {
+				hasRun = append(hasRun, check)
+			}
+		}
+		status = hc.areChecksHealthy(hasRun)
+		return status, status != StatusCritical
+	case ProbeStartup:
+		if hc.areChecksStartingUp(checks) {
+			return StatusWarning, false
+		}
+		return hc.areChecksHealthy(checks), true
+	default:
+		if status, ok := hc.getForcedStatus(); ok {
+			return status, status != StatusCritical
+		}
+		if hc.areChecksStartingUp(checks) {
+			return StatusWarning, false
+		}
+		status = hc.areChecksHealthy(checks)
+		return status, status != StatusCritical
+	}
+}
@@ -0,0 +1,166 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithProbes(t *testing.T) {
+	Convey("Given a check without any probes", t, func() {
+		check := &Check{}
+
+		Convey("Then it takes part in readiness and startup only", func() {
+			So(check.inProbe(ProbeLiveness), ShouldBeFalse)
+			So(check.inProbe(ProbeReadiness), ShouldBeTrue)
+			So(check.inProbe(ProbeStartup), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given a check created with liveness and readiness probes", t, func() {
+		check := &Check{}
+		WithProbes(ProbeLiveness, ProbeReadiness)(check)
+
+		Convey("Then it takes part in liveness and readiness only", func() {
+			So(check.inProbe(ProbeLiveness), ShouldBeTrue)
+			So(check.inProbe(ProbeReadiness), ShouldBeTrue)
+			So(check.inProbe(ProbeStartup), ShouldBeFalse)
+		})
+	})
+}
+
+func TestProbeHandlers(t *testing.T) {
+	t0 := time.Now().UTC()
+	t20 := t0.Add(-20 * time.Minute) // 20 min ago
+	t30 := t0.Add(-30 * time.Minute) // 30 min ago
+
+	okState := CheckState{
+		name:        "ok check",
+		status:      StatusOK,
+		lastChecked: &t0,
+		lastSuccess: &t0,
+	}
+	warningState := CheckState{
+		name:        "warning check",
+		status:      StatusWarning,
+		lastChecked: &t0,
+		lastFailure: &t0,
+	}
+	criticalState := CheckState{
+		name:          "critical check",
+		status:        StatusCritical,
+		lastChecked:   &t0,
+		lastSuccess:   &t30,
+		lastFailure:   &t0,
+		criticalSince: &t20,
+	}
+	notRunState := CheckState{
+		name: "not run check",
+	}
+
+	createCheck := func(state CheckState, probes ...Probe) *Check {
+		check := createATestCheck(state, state.lastChecked != nil)
+		if len(probes) > 0 {
+			WithProbes(probes...)(check)
+		}
+		return check
+	}
+
+	callHandler := func(handler http.HandlerFunc) (int, probeResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+		handler(w, req)
+
+		var resp probeResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		So(err, ShouldBeNil)
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+		return w.Code, resp
+	}
+
+	Convey("Given a healthcheck with a check that has been CRITICAL beyond the critical timeout", t, func() {
+		hc := getTestHealthCheck(t30, 10*time.Minute)
+		hc.Checks = []*Check{createCheck(okState), createCheck(criticalState)}
+
+		Convey("Then the liveness probe passes because no checks take part in liveness by default", func() {
+			code, resp := callHandler(hc.LivenessHandler)
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp.Status, ShouldEqual, StatusOK)
+			So(resp.Checks, ShouldHaveLength, 0)
+		})
+
+		Convey("Then the readiness probe fails", func() {
+			code, resp := callHandler(hc.ReadinessHandler)
+			So(code, ShouldEqual, http.StatusServiceUnavailable)
+			So(resp.Status, ShouldEqual, StatusCritical)
+			So(resp.Checks, ShouldHaveLength, 2)
+		})
+
+		Convey("Then the startup probe passes because all checks have run", func() {
+			code, resp := callHandler(hc.StartupHandler)
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp.Status, ShouldEqual, StatusCritical)
+		})
+	})
+
+	Convey("Given a healthcheck with a CRITICAL check that takes part in liveness", t, func() {
+		hc := getTestHealthCheck(t30, 10*time.Minute)
+		hc.Checks = []*Check{createCheck(okState), createCheck(criticalState, ProbeLiveness)}
+
+		Convey("Then the liveness probe fails", func() {
+			code, resp := callHandler(hc.LivenessHandler)
+			So(code, ShouldEqual, http.StatusServiceUnavailable)
+			So(resp.Status, ShouldEqual, StatusCritical)
+			So(resp.Checks, ShouldHaveLength, 1)
+		})
+
+		Convey("Then the readiness probe passes because the CRITICAL check does not take part in readiness", func() {
+			code, resp := callHandler(hc.ReadinessHandler)
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp.Status, ShouldEqual, StatusOK)
+			So(resp.Checks, ShouldHaveLength, 1)
+		})
+	})
+
+	Convey("Given a healthcheck with a WARNING check", t, func() {
+		hc := getTestHealthCheck(t30, 10*time.Minute)
+		hc.Checks = []*Check{createCheck(okState), createCheck(warningState, ProbeLiveness, ProbeReadiness)}
+
+		Convey("Then the liveness and readiness probes pass", func() {
+			code, resp := callHandler(hc.LivenessHandler)
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp.Status, ShouldEqual, StatusWarning)
+
+			code, resp = callHandler(hc.ReadinessHandler)
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp.Status, ShouldEqual, StatusWarning)
+		})
+	})
+
+	Convey("Given a healthcheck that is still starting up", t, func() {
+		hc := getTestHealthCheck(t0, 10*time.Minute)
+		hc.Checks = []*Check{createCheck(okState), createCheck(notRunState, ProbeLiveness, ProbeReadiness, ProbeStartup)}
+
+		Convey("Then the liveness probe passes, ignoring the checks that have not run yet", func() {
+			code, resp := callHandler(hc.LivenessHandler)
+			So(code, ShouldEqual, http.StatusOK)
+			So(resp.Status, ShouldEqual, StatusOK)
+		})
+
+		Convey("Then the readiness probe fails", func() {
+			code, resp := callHandler(hc.ReadinessHandler)
+			So(code, ShouldEqual, http.StatusServiceUnavailable)
+			So(resp.Status, ShouldEqual, StatusWarning)
+		})
+
+		Convey("Then the startup probe fails", func() {
+			code, resp := callHandler(hc.StartupHandler)
+			So(code, ShouldEqual, http.StatusServiceUnavailable)
+			So(resp.Status, ShouldEqual, StatusWarning)
+		})
+	})
+}
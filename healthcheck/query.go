@@ -0,0 +1,106 @@
+package healthcheck
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// healthQuery represents the query parameters of a health check request
+type healthQuery struct {
+	group    string
+	names    map[string]bool
+	statuses map[string]bool
+	verbose  bool
+}
+
+// statusResponse represents the health check response when only the status has been requested
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+// parseHealthQuery returns the query parameters of the provided health check request:
+// 'group' to get the checks tagged with a group only, 'check' and 'status' to get the checks with the provided
+// comma separated names and statuses only, and 'verbose=false' to get the status only
+func parseHealthQuery(req *http.Request) (healthQuery, error) {
+	query := req.URL.Query()
+	q := healthQuery{
+		group:   query.Get("group"),
+		verbose: true,
+	}
+
+	for _, param := range query["check"] {
+		for _, name := range strings.Split(param, ",") {
+			if q.names == nil {
+				q.names = map[string]bool{}
+			}
+			q.names[name] = true
+		}
+	}
+
+	for _, param := range query["status"] {
+		for _, status := range strings.Split(param, ",") {
+			status = strings.ToUpper(status)
+			if !isValidStatus(status) {
+				return q, fmt.Errorf("invalid status parameter, must be one of %s, %s or %s", StatusOK, StatusWarning, StatusCritical)
+			}
+			if q.statuses == nil {
+				q.statuses = map[string]bool{}
+			}
+			q.statuses[status] = true
+		}
+	}
+
+	if verbose := query.Get("verbose"); verbose != "" {
+		var err error
+		if q.verbose, err = strconv.ParseBool(verbose); err != nil {
+			return q, fmt.Errorf("invalid verbose parameter, must be true or false")
+		}
+	}
+
+	return q, nil
+}
+
+// isFiltered returns true if the query only asks for some of the checks
+func (q healthQuery) isFiltered() bool {
+	return q.group != "" || q.names != nil || q.statuses != nil
+}
+
+// filterChecks returns the checks matching the query. An error is returned if the query asks for a group or for
+// checks that do not exist, whereas no checks are returned if none has the requested status, which is the status
+// forced by an override for a check that has one.
+// The caller must hold the statusLock.
+func (hc *HealthCheck) filterChecks(q healthQuery) ([]*Check, error) {
+	checks := hc.Checks
+	if q.group != "" {
+		if checks = hc.getGroupChecks(q.group); len(checks) == 0 {
+			return nil, fmt.Errorf("group not found: %s", q.group)
+		}
+	}
+
+	if q.names != nil {
+		var named []*Check
+		for _, check := range checks {
+			if q.names[check.state.Name()] {
+				named = append(named, check)
+			}
+		}
+		if len(named) == 0 {
+			return nil, fmt.Errorf("no check found with any of the requested names")
+		}
+		checks = named
+	}
+
+	if q.statuses != nil {
+		filtered := []*Check{}
+		for _, check := range checks {
+			if q.statuses[check.state.reportedStatus()] {
+				filtered = append(filtered, check)
+			}
+		}
+		checks = filtered
+	}
+
+	return checks, nil
+}
@@ -0,0 +1,184 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHandlerQuery(t *testing.T) {
+	t0 := time.Now().UTC()
+	t20 := t0.Add(-20 * time.Minute) // 20 min ago
+
+	okState := CheckState{name: "mongodb", status: StatusOK, lastChecked: &t0, lastSuccess: &t0}
+	warningState := CheckState{name: "s3", status: StatusWarning, lastChecked: &t0, lastFailure: &t0}
+	criticalState := CheckState{name: "kafka", status: StatusCritical, lastChecked: &t0, lastFailure: &t0, criticalSince: &t20}
+
+	callHandler := func(hc *HealthCheck, method, target string, accept string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req := httptest.NewRequest(method, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		hc.Handler(w, req)
+
+		var body map[string]interface{}
+		if w.Code != http.StatusBadRequest && w.Code != http.StatusNotFound && w.Body.Len() > 0 {
+			So(json.Unmarshal(w.Body.Bytes(), &body), ShouldBeNil)
+		}
+		return w, body
+	}
+
+	checkNames := func(body map[string]interface{}) []string {
+		names := []string{}
+		for _, check := range body["checks"].([]interface{}) {
+			names = append(names, check.(map[string]interface{})["name"].(string))
+		}
+		return names
+	}
+
+	Convey("Given a healthcheck with an OK, a WARNING and a CRITICAL check", t, func() {
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		hc.Checks = createChecksSlice([]CheckState{okState, warningState, criticalState}, true)
+		WithTags("storage")(hc.Checks[0])
+		WithTags("storage")(hc.Checks[1])
+
+		Convey("When the handler is called with verbose=false", func() {
+			w, body := callHandler(&hc, http.MethodGet, "/health?verbose=false", "")
+
+			Convey("Then only the status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(body, ShouldResemble, map[string]interface{}{"status": StatusCritical})
+			})
+		})
+
+		Convey("When the handler is called with verbose=false asking for the IETF format", func() {
+			w, body := callHandler(&hc, http.MethodGet, "/health?verbose=false", ietfContentType)
+
+			Convey("Then only the IETF status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+				So(w.Header().Get("Content-Type"), ShouldEqual, ietfContentType)
+				So(body, ShouldResemble, map[string]interface{}{"status": ietfStatusFail})
+			})
+		})
+
+		Convey("When the handler is called with an invalid verbose parameter", func() {
+			w, _ := callHandler(&hc, http.MethodGet, "/health?verbose=maybe", "")
+
+			Convey("Then a 400 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
+		Convey("When the handler is called for some checks", func() {
+			w, body := callHandler(&hc, http.MethodGet, "/health?check=mongodb,s3", "")
+
+			Convey("Then only those checks are returned, with their status and status code", func() {
+				So(w.Code, ShouldEqual, http.StatusTooManyRequests)
+				So(body["status"], ShouldEqual, StatusWarning)
+				So(checkNames(body), ShouldResemble, []string{"mongodb", "s3"})
+			})
+
+			Convey("Then the app status is left untouched", func() {
+				So(hc.GetStatus(), ShouldEqual, StatusCritical)
+			})
+		})
+
+		Convey("When the handler is called for checks that do not exist", func() {
+			w, _ := callHandler(&hc, http.MethodGet, "/health?check=unknown", "")
+
+			Convey("Then a 404 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When the handler is called for the CRITICAL checks", func() {
+			w, body := callHandler(&hc, http.MethodGet, "/health?status=CRITICAL", "")
+
+			Convey("Then only the failing checks are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(body["status"], ShouldEqual, StatusCritical)
+				So(checkNames(body), ShouldResemble, []string{"kafka"})
+			})
+		})
+
+		Convey("When the handler is called for the CRITICAL checks of a group without any", func() {
+			w, body := callHandler(&hc, http.MethodGet, "/health?group=storage&status=critical", "")
+
+			Convey("Then no checks are returned, and the status is OK", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(body["status"], ShouldEqual, StatusOK)
+				So(checkNames(body), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the handler is called for several statuses", func() {
+			w, body := callHandler(&hc, http.MethodGet, "/health?status=WARNING&status=OK", "")
+
+			Convey("Then the checks with any of them are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusTooManyRequests)
+				So(checkNames(body), ShouldResemble, []string{"mongodb", "s3"})
+			})
+		})
+
+		Convey("When the handler is called for the CRITICAL checks once the CRITICAL check has been overridden to OK", func() {
+			hc.Checks[2].state.setOverride(&Override{Status: StatusOK, Reason: "known issue"}, nil)
+			w, body := callHandler(&hc, http.MethodGet, "/health?status=CRITICAL", "")
+
+			Convey("Then the overridden check is not returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(checkNames(body), ShouldBeEmpty)
+			})
+
+			Convey("Then the overridden check is returned for its forced status", func() {
+				_, body := callHandler(&hc, http.MethodGet, "/health?status=OK", "")
+				So(checkNames(body), ShouldResemble, []string{"mongodb", "kafka"})
+			})
+		})
+
+		Convey("When the handler is called with an invalid status parameter", func() {
+			w, _ := callHandler(&hc, http.MethodGet, "/health?status=BROKEN", "")
+
+			Convey("Then a 400 is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
+		Convey("When the handler is called with a HEAD request", func() {
+			w, _ := callHandler(&hc, http.MethodHead, "/health", "")
+
+			Convey("Then only the status code is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/json; charset=utf-8")
+				So(w.Body.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the handler is called with a HEAD request for some checks", func() {
+			w, _ := callHandler(&hc, http.MethodHead, "/health?check=mongodb", "")
+
+			Convey("Then the status code is the one of those checks", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.Len(), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When the group handler is called for the WARNING checks of its group", func() {
+			req := httptest.NewRequest(http.MethodGet, "/health/storage?status=WARNING&group=other", nil)
+			w := httptest.NewRecorder()
+			hc.GroupHandler("storage")(w, req)
+
+			var body map[string]interface{}
+			So(json.Unmarshal(w.Body.Bytes(), &body), ShouldBeNil)
+
+			Convey("Then only the WARNING checks of its group are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusTooManyRequests)
+				So(checkNames(body), ShouldResemble, []string{"s3"})
+			})
+		})
+	})
+}
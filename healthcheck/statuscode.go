@@ -0,0 +1,61 @@
+package healthcheck
+
+import "net/http"
+
+// StatusStartingUp is used with WithStatusCode to set the status code of the response while the app is starting up,
+// i.e. while any check has not run yet. The app status is WARNING until then.
+const StatusStartingUp = "STARTING_UP"
+
+// statusCodeMapping is the status code, and headers, of the response of Handler for a status
+type statusCodeMapping struct {
+	code    int
+	headers http.Header
+}
+
+// WithStatusCode sets the status code, and any headers such as Retry-After, of the response of Handler for the
+// provided status, which must be one of StatusOK, StatusWarning, StatusCritical or StatusStartingUp. A code of 0
+// keeps the default code. The mapping applies to every response format. By default, the status code is 200 for OK,
+// 429 for WARNING and 500 for CRITICAL, or 200 for OK and WARNING and 503 for CRITICAL in the IETF format, and the
+// status code for WARNING is used while the app is starting up.
+func WithStatusCode(status string, code int, headers http.Header) Option {
+	return func(hc *HealthCheck) {
+		if hc.statusCodes == nil {
+			hc.statusCodes = map[string]statusCodeMapping{}
+		}
+		hc.statusCodes[status] = statusCodeMapping{code: code, headers: headers}
+	}
+}
+
+// getStatusCode returns the status code, and headers, of the response for the provided status in the provided format.
+// The mapping for StatusStartingUp, if any, is used instead if the checks are starting up.
+func (hc *HealthCheck) getStatusCode(status string, startingUp bool, format ResponseFormat) (int, http.Header) {
+	mapping, ok := hc.statusCodes[StatusStartingUp]
+	if !startingUp || !ok {
+		mapping = hc.statusCodes[status]
+	}
+	if mapping.code == 0 {
+		mapping.code = getDefaultStatusCode(status, format)
+	}
+	return mapping.code, mapping.headers
+}
+
+// getDefaultStatusCode returns the status code of the response for the provided status in the provided format,
+// when no mapping has been set
+func getDefaultStatusCode(status string, format ResponseFormat) int {
+	if format == FormatIETF {
+		// as defined by the format, the status code is 200 for a pass or warn status and 503 for a fail status
+		if status == StatusCritical {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusOK
+	}
+
+	switch status {
+	case StatusOK:
+		return http.StatusOK
+	case StatusWarning:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
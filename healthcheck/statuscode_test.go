@@ -0,0 +1,127 @@
+package healthcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithStatusCode(t *testing.T) {
+	t0 := time.Now().UTC()
+	t20 := t0.Add(-20 * time.Minute) // 20 min ago
+
+	okState := CheckState{name: "mongodb", status: StatusOK, lastChecked: &t0, lastSuccess: &t0}
+	warningState := CheckState{name: "s3", status: StatusWarning, lastChecked: &t0, lastFailure: &t0}
+	criticalState := CheckState{name: "kafka", status: StatusCritical, lastChecked: &t0, lastFailure: &t0, criticalSince: &t20}
+
+	callHandler := func(hc *HealthCheck, target, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		hc.Handler(w, req)
+		return w
+	}
+
+	Convey("Given a healthcheck with custom status codes", t, func() {
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		WithStatusCode(StatusWarning, http.StatusOK, nil)(&hc)
+		WithStatusCode(StatusCritical, http.StatusServiceUnavailable, http.Header{"retry-after": {"30"}})(&hc)
+		WithStatusCode(StatusStartingUp, http.StatusServiceUnavailable, http.Header{"Retry-After": {"10"}})(&hc)
+
+		Convey("When the app is OK", func() {
+			hc.Checks = createChecksSlice([]CheckState{okState}, true)
+			w := callHandler(&hc, "/health", "")
+
+			Convey("Then the default status code is returned without any headers", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Retry-After"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the app is WARNING", func() {
+			hc.Checks = createChecksSlice([]CheckState{okState, warningState}, true)
+			w := callHandler(&hc, "/health", "")
+
+			Convey("Then the custom status code is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+		})
+
+		Convey("When the app is CRITICAL", func() {
+			hc.Checks = createChecksSlice([]CheckState{okState, criticalState}, true)
+
+			Convey("Then the custom status code and headers are returned", func() {
+				w := callHandler(&hc, "/health", "")
+				So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+				So(w.Header().Get("Retry-After"), ShouldEqual, "30")
+			})
+
+			Convey("Then the custom status code and headers are returned in the IETF format too", func() {
+				w := callHandler(&hc, "/health", ietfContentType)
+				So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+				So(w.Header().Get("Retry-After"), ShouldEqual, "30")
+			})
+
+			Convey("Then the status code of the requested checks is returned when they are filtered", func() {
+				w := callHandler(&hc, "/health?check=mongodb", "")
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Retry-After"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the app is starting up", func() {
+			hc.Checks = []*Check{createATestCheck(okState, true), createATestCheck(CheckState{name: "not run"}, false)}
+			w := callHandler(&hc, "/health", "")
+
+			Convey("Then the status code and headers for starting up are returned", func() {
+				So(hc.GetStatus(), ShouldEqual, StatusWarning)
+				So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+				So(w.Header().Get("Retry-After"), ShouldEqual, "10")
+			})
+
+			Convey("Then the status code of the requested checks is returned when they have all run", func() {
+				w := callHandler(&hc, "/health?check=mongodb", "")
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+		})
+
+		Convey("When the app is starting up but its status has been forced", func() {
+			hc.Checks = createChecksSlice([]CheckState{{name: "not run"}}, false)
+			hc.Override = &Override{Status: StatusWarning, Reason: "maintenance"}
+			w := callHandler(&hc, "/health", "")
+
+			Convey("Then the status code for the forced status is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Retry-After"), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given a healthcheck with a custom header but the default status code for WARNING", t, func() {
+		hc := getTestHealthCheck(t20, 10*time.Minute)
+		WithStatusCode(StatusWarning, 0, http.Header{"Retry-After": {"60"}})(&hc)
+		hc.Checks = createChecksSlice([]CheckState{warningState}, true)
+
+		Convey("Then the default status code is returned with the header, in every format", func() {
+			w := callHandler(&hc, "/health", "")
+			So(w.Code, ShouldEqual, http.StatusTooManyRequests)
+			So(w.Header().Get("Retry-After"), ShouldEqual, "60")
+
+			w = callHandler(&hc, "/health", ietfContentType)
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Header().Get("Retry-After"), ShouldEqual, "60")
+		})
+
+		Convey("Then the default status code for WARNING is used while the app is starting up", func() {
+			hc.Checks = createChecksSlice([]CheckState{{name: "not run"}}, false)
+			w := callHandler(&hc, "/health", "")
+			So(w.Code, ShouldEqual, http.StatusTooManyRequests)
+			So(w.Header().Get("Retry-After"), ShouldEqual, "60")
+		})
+	})
+}
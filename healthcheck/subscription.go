@@ -3,7 +3,6 @@ package healthcheck
 import (
 	"context"
 	"sync"
-	"time"
 )
 
 //go:generate moq -out ./mock/subscription.go -pkg mock . Subscriber
@@ -39,6 +38,10 @@ func (hc *HealthCheck) SubscribeAll(s Subscriber) {
 	hc.subsMutex.Lock()
 	defer hc.subsMutex.Unlock()
 
+	// the checks may be removed or replaced at runtime, under the status lock
+	hc.statusLock.RLock()
+	defer hc.statusLock.RUnlock()
+
 	hc.subscribers[s] = map[*Check]struct{}{}
 	for _, check := range hc.Checks {
 		hc.subscribers[s][check] = struct{}{}
@@ -63,7 +66,7 @@ func (hc *HealthCheck) Unsubscribe(s Subscriber, checks ...*Check) {
 
 	// if the subscriber is empty, it is removed.
 	if len(subscribed) == 0 {
-		delete(hc.subscribers, s)
+		hc.removeSubscriber(s)
 	}
 }
 
@@ -71,37 +74,51 @@ func (hc *HealthCheck) Unsubscribe(s Subscriber, checks ...*Check) {
 func (hc *HealthCheck) UnsubscribeAll(s Subscriber) {
 	hc.subsMutex.Lock()
 	defer hc.subsMutex.Unlock()
-	delete(hc.subscribers, s)
+	hc.removeSubscriber(s)
 }
 
+// healthChangeCallback notifies all subscribers and watches of a change that was not triggered by a check state change,
+// and updates the app status
 func (hc *HealthCheck) healthChangeCallback() *sync.WaitGroup {
+	return hc.notifyHealthChange(nil)
+}
+
+// checkChangeCallback returns the callback that is triggered when the state of the provided check changes
+func (hc *HealthCheck) checkChangeCallback(check *Check) func() *sync.WaitGroup {
+	return func() *sync.WaitGroup {
+		return hc.notifyHealthChange(check)
+	}
+}
+
+// notifyHealthChange notifies all subscribers and watches of a change triggered by the provided check, if any,
+// and updates the app status, making any cached responses stale.
+// Every subscriber is sent its new status by its own dispatcher, in order and without blocking the caller. If the subscriber
+// is still handling a previous status, only the latest status is sent once it is done.
+// The returned wait group is done once every subscriber has handled its new status, or has been unsubscribed.
+func (hc *HealthCheck) notifyHealthChange(check *Check) *sync.WaitGroup {
 	wg := &sync.WaitGroup{}
+	ctx := context.Background()
 
 	hc.subsMutex.Lock()
 	defer hc.subsMutex.Unlock()
 
+	hc.statusLock.Lock()
+	defer hc.statusLock.Unlock()
+
 	// Notify all subscribers of the new health state for their subscribed checkers
 	for s, checks := range hc.subscribers {
 		checkList := []*Check{}
 		for check := range checks {
 			checkList = append(checkList, check)
 		}
-		status := hc.getChecksStatus(checkList)
-		wg.Add(1)
-		go func(subscriber Subscriber) {
-			defer wg.Done()
-			subscriber.OnHealthUpdate(status)
-		}(s)
+		hc.dispatch(s, hc.getChecksStatus(checkList), wg)
 	}
 
-	// Update global app status (after acquiring the statusLock), so that we don't rely on `/health` being called
-	hc.statusLock.Lock()
-	defer hc.statusLock.Unlock()
-
-	now := time.Now().UTC()
-	newStatus := hc.getAppStatus(context.Background())
-	hc.Status = newStatus
-	hc.Uptime = now.Sub(hc.StartTime) / time.Millisecond
+	// Update global app status, so that we don't rely on `/health` being called
+	hc.updateStatus(ctx)
+	hc.scheduleEscalation()
+	hc.invalidateResponseCache()
+	hc.notifyWatches(check)
 
 	return wg
 }
@@ -19,6 +19,7 @@ func TestSubscribe(t *testing.T) {
 		hc := &HealthCheck{
 			subscribers: map[Subscriber]map[*Check]struct{}{},
 			subsMutex:   &sync.Mutex{},
+			statusLock:  &sync.RWMutex{},
 			Checks:      []*Check{c1, c2, c3},
 		}
 
@@ -119,10 +120,11 @@ func TestNotifyHealthUpdate(t *testing.T) {
 		lastFailure: &t10,
 	}, true)
 	c3 := createATestCheck(CheckState{
-		status:      StatusCritical,
-		lastChecked: &t0,
-		lastSuccess: &t0,
-		lastFailure: &t10,
+		status:        StatusCritical,
+		lastChecked:   &t0,
+		lastSuccess:   &t10,
+		lastFailure:   &t0,
+		criticalSince: &t0,
 	}, true)
 
 	Convey("Given a healthcheck with a total of 3 checks, 2 subscribers and 2 checkers per subscriber", t, func() {
@@ -142,9 +144,9 @@ func TestNotifyHealthUpdate(t *testing.T) {
 			wg.Wait()
 			So(sub1.OnHealthUpdateCalls(), ShouldHaveLength, 1)
 			So(sub2.OnHealthUpdateCalls(), ShouldHaveLength, 1)
-			So(sub1.OnHealthUpdateCalls()[0].Status, ShouldEqual, StatusOK)      // combined status of {c1, c2}
-			So(sub2.OnHealthUpdateCalls()[0].Status, ShouldEqual, StatusWarning) // combined status of {c2, c3}
-			So(hc.GetStatus(), ShouldEqual, StatusCritical)                      // app status after callback
+			So(sub1.OnHealthUpdateCalls()[0].Status, ShouldEqual, StatusOK)       // combined status of {c1, c2}
+			So(sub2.OnHealthUpdateCalls()[0].Status, ShouldEqual, StatusCritical) // combined status of {c2, c3}
+			So(hc.GetStatus(), ShouldEqual, StatusCritical)                       // app status after callback
 		})
 	})
 }
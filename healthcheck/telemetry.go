@@ -0,0 +1,104 @@
+package healthcheck
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the OpenTelemetry tracer and meter used by the health check
+const instrumentationName = "github.com/ONSdigital/dp-healthcheck/healthcheck"
+
+// A list of the OpenTelemetry attributes recorded for each check run
+const (
+	attributeCheckName       = attribute.Key("healthcheck.check.name")
+	attributeCheckStatus     = attribute.Key("healthcheck.check.status")
+	attributeCheckStatusCode = attribute.Key("healthcheck.check.status_code")
+	attributeCheckMessage    = attribute.Key("healthcheck.check.message")
+	attributeCheckError      = attribute.Key("healthcheck.check.error")
+)
+
+// telemetry records OpenTelemetry spans and metrics for each check run
+type telemetry struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	runs     metric.Int64Counter
+}
+
+// newTelemetry returns a pointer to a new instantiated telemetry, using the global providers if none are provided
+func newTelemetry(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) *telemetry {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+
+	meter := meterProvider.Meter(instrumentationName)
+	t := &telemetry{
+		tracer: tracerProvider.Tracer(instrumentationName),
+	}
+
+	var err error
+	t.duration, err = meter.Float64Histogram("healthcheck.check.duration",
+		metric.WithDescription("Duration of the check runs"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	t.runs, err = meter.Int64Counter("healthcheck.check.runs",
+		metric.WithDescription("Number of check runs, by resulting status"),
+		metric.WithUnit("{run}"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return t
+}
+
+// startCheckSpan starts a span for a run of the check with the provided name
+func (t *telemetry) startCheckSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "healthcheck.check",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attributeCheckName.String(name)),
+	)
+}
+
+// endCheckSpan records the result of a check run on the provided span and in the metrics, then ends the span
+func (t *telemetry) endCheckSpan(ctx context.Context, span trace.Span, state *CheckState, duration time.Duration, err error) {
+	status := state.Status()
+	message := state.Message()
+
+	span.SetAttributes(
+		attributeCheckStatus.String(status),
+		attributeCheckStatusCode.Int(state.StatusCode()),
+		attributeCheckMessage.String(message),
+	)
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case status == StatusCritical:
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+
+	attrs := metric.WithAttributes(
+		attributeCheckName.String(state.Name()),
+		attributeCheckStatus.String(status),
+		attributeCheckError.Bool(err != nil),
+	)
+	if t.duration != nil {
+		t.duration.Record(ctx, duration.Seconds(), attrs)
+	}
+	if t.runs != nil {
+		t.runs.Add(ctx, 1, attrs)
+	}
+}
@@ -0,0 +1,113 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTelemetry(t *testing.T) {
+	Convey("Given a Health Check with in-memory tracer and meter providers", t, func() {
+		exporter := tracetest.NewInMemoryExporter()
+		tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		reader := sdkmetric.NewManualReader()
+		meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+		hc := New(version, criticalTimeout, time.Hour, WithTracerProvider(tracerProvider), WithMeterProvider(meterProvider))
+
+		Convey("When a check that is CRITICAL is run", func() {
+			err := hc.AddCheck("mongodb", func(ctx context.Context, state *CheckState) error {
+				return state.Update(StatusCritical, "mongodb is unreachable", 503)
+			})
+			So(err, ShouldBeNil)
+			hc.Start(context.Background())
+			hc.Stop()
+
+			Convey("Then a span is recorded with the check result as attributes", func() {
+				spans := exporter.GetSpans()
+				So(spans, ShouldHaveLength, 1)
+				So(spans[0].Name, ShouldEqual, "healthcheck.check")
+				So(spans[0].Attributes, ShouldContain, attribute.String("healthcheck.check.name", "mongodb"))
+				So(spans[0].Attributes, ShouldContain, attribute.String("healthcheck.check.status", StatusCritical))
+				So(spans[0].Attributes, ShouldContain, attribute.Int("healthcheck.check.status_code", 503))
+				So(spans[0].Attributes, ShouldContain, attribute.String("healthcheck.check.message", "mongodb is unreachable"))
+				So(spans[0].Status.Code, ShouldEqual, codes.Error)
+			})
+
+			Convey("Then the duration and outcome of the run are recorded as metrics", func() {
+				var rm metricdata.ResourceMetrics
+				err := reader.Collect(context.Background(), &rm)
+				So(err, ShouldBeNil)
+				So(rm.ScopeMetrics, ShouldHaveLength, 1)
+				So(rm.ScopeMetrics[0].Scope.Name, ShouldEqual, instrumentationName)
+
+				metrics := map[string]metricdata.Metrics{}
+				for _, m := range rm.ScopeMetrics[0].Metrics {
+					metrics[m.Name] = m
+				}
+
+				duration, ok := metrics["healthcheck.check.duration"].Data.(metricdata.Histogram[float64])
+				So(ok, ShouldBeTrue)
+				So(duration.DataPoints, ShouldHaveLength, 1)
+				So(duration.DataPoints[0].Count, ShouldEqual, 1)
+				name, _ := duration.DataPoints[0].Attributes.Value("healthcheck.check.name")
+				So(name.AsString(), ShouldEqual, "mongodb")
+
+				runs, ok := metrics["healthcheck.check.runs"].Data.(metricdata.Sum[int64])
+				So(ok, ShouldBeTrue)
+				So(runs.DataPoints, ShouldHaveLength, 1)
+				So(runs.DataPoints[0].Value, ShouldEqual, 1)
+				status, _ := runs.DataPoints[0].Attributes.Value("healthcheck.check.status")
+				So(status.AsString(), ShouldEqual, StatusCritical)
+				failed, _ := runs.DataPoints[0].Attributes.Value("healthcheck.check.error")
+				So(failed.AsBool(), ShouldBeFalse)
+			})
+		})
+
+		Convey("When a checker that returns an error is run", func() {
+			err := hc.AddCheck("kafka", func(ctx context.Context, state *CheckState) error {
+				return errors.New("checker failed")
+			})
+			So(err, ShouldBeNil)
+			hc.Start(context.Background())
+			hc.Stop()
+
+			Convey("Then the error is recorded on the span", func() {
+				spans := exporter.GetSpans()
+				So(spans, ShouldHaveLength, 1)
+				So(spans[0].Status.Code, ShouldEqual, codes.Error)
+				So(spans[0].Status.Description, ShouldEqual, "checker failed")
+				So(spans[0].Events, ShouldHaveLength, 1)
+				So(spans[0].Events[0].Name, ShouldEqual, "exception")
+			})
+		})
+
+		Convey("When an OK check is run", func() {
+			var spanContextValid bool
+			err := hc.AddCheck("cache", func(ctx context.Context, state *CheckState) error {
+				spanContextValid = trace.SpanContextFromContext(ctx).IsValid()
+				return state.Update(StatusOK, "ok", 0)
+			})
+			So(err, ShouldBeNil)
+			hc.Start(context.Background())
+			hc.Stop()
+
+			Convey("Then the checker is called with the span in its context and the span status is not an error", func() {
+				So(spanContextValid, ShouldBeTrue)
+				spans := exporter.GetSpans()
+				So(spans, ShouldHaveLength, 1)
+				So(spans[0].Status.Code, ShouldEqual, codes.Unset)
+			})
+		})
+	})
+}
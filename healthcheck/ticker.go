@@ -2,6 +2,8 @@ package healthcheck
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -9,20 +11,29 @@ import (
 )
 
 type ticker struct {
-	timeTicker *time.Ticker
+	timeTicker Ticker
+	interval   time.Duration
 	closing    chan bool
+	closeOnce  *sync.Once
 	closed     chan bool
 	check      *Check
+	telemetry  *telemetry
+	clock      Clock
 }
 
-// createTicker will create a ticker that calls an individual check's checker function at the provided interval
-func createTicker(interval time.Duration, check *Check) *ticker {
+// createTicker will create a ticker that calls an individual check's checker function at the provided interval,
+// recording every run with the provided telemetry and timing it with the provided clock
+func createTicker(interval time.Duration, check *Check, telemetry *telemetry, clock Clock) *ticker {
 	intervalWithJitter := calcIntervalWithJitter(interval)
 	return &ticker{
-		timeTicker: time.NewTicker(intervalWithJitter),
+		timeTicker: clock.NewTicker(intervalWithJitter),
+		interval:   intervalWithJitter,
 		closing:    make(chan bool),
+		closeOnce:  &sync.Once{},
 		closed:     make(chan bool),
 		check:      check,
+		telemetry:  telemetry,
+		clock:      clock,
 	}
 }
 
@@ -31,6 +42,22 @@ func (ticker *ticker) start(ctx context.Context, wg *sync.WaitGroup) {
 	go func() {
 		defer close(ticker.closed)
 
+		// wait for the initial delay, if any, before the first run
+		if ticker.check.initialDelay > 0 {
+			delay := ticker.clock.NewTimer(ticker.check.initialDelay)
+			select {
+			case <-delay.C():
+				// restart the ticker so that the interval is counted from the first run
+				ticker.timeTicker.Reset(ticker.interval)
+			case <-ctx.Done():
+				delay.Stop()
+				return
+			case <-ticker.closing:
+				delay.Stop()
+				return
+			}
+		}
+
 		// first run check once on application start
 		wg.Add(1)
 		ticker.runCheck(ctx, wg)
@@ -38,10 +65,12 @@ func (ticker *ticker) start(ctx context.Context, wg *sync.WaitGroup) {
 		for {
 			select {
 			case <-ctx.Done():
-				ticker.stop()
+				// the goroutine cannot wait for itself to be closed, so it only marks the ticker as stopping
+				ticker.close()
+				return
 			case <-ticker.closing:
 				return
-			case <-ticker.timeTicker.C:
+			case <-ticker.timeTicker.C():
 				wg.Add(1)
 				go ticker.runCheck(ctx, wg)
 			}
@@ -53,7 +82,28 @@ func (ticker *ticker) start(ctx context.Context, wg *sync.WaitGroup) {
 func (ticker *ticker) runCheck(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	err := ticker.check.checker(ctx, ticker.check.state)
+	if ticker.check.abandoned.Load() {
+		log.Warn(ctx, "check run skipped as the checker of its previous run timed out and has not returned yet",
+			log.Data{"external_service": ticker.check.state.Name()})
+		return
+	}
+
+	ctx, span := ticker.telemetry.startCheckSpan(ctx, ticker.check.state.Name())
+
+	start := ticker.clock.Now()
+	var err error
+	if ticker.check.timeout > 0 {
+		err = ticker.runCheckWithTimeout(ctx, start)
+	} else {
+		err = ticker.runChecker(ctx, start)
+	}
+	duration := ticker.clock.Now().Sub(start)
+
+	ticker.telemetry.endCheckSpan(ctx, span, ticker.check.state, duration, err)
+	if ticker.check.metrics != nil {
+		failed := err != nil || ticker.check.state.Status() != StatusOK
+		ticker.check.metrics.recordRun(duration, failed)
+	}
 	if err != nil {
 		name := "no check has been made yet"
 		if ticker.check.state != nil {
@@ -64,16 +114,73 @@ func (ticker *ticker) runCheck(ctx context.Context, wg *sync.WaitGroup) {
 	}
 }
 
-// stop the ticker
-func (ticker *ticker) stop() {
-	if ticker.isStopping() {
+// runChecker runs the checker of the check, recording the duration of the run, which started at the provided time,
+// with any state transition
+func (ticker *ticker) runChecker(ctx context.Context, start time.Time) error {
+	err := ticker.check.checker(ctx, ticker.check.state)
+	ticker.check.state.setRunDuration(start)
+	return err
+}
+
+// runCheckWithTimeout runs the checker with a context that is cancelled once the check timeout expires.
+// If the timeout expires before the checker returns, the check state is set to CRITICAL straight away and the run ends,
+// even if the checker ignores its context. The checker is then left to return in the background, and the next runs
+// of the check are skipped until it does.
+func (ticker *ticker) runCheckWithTimeout(ctx context.Context, start time.Time) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, ticker.check.timeout)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ticker.check.checker(timeoutCtx, ticker.check.state)
+	}()
+
+	select {
+	case err := <-done:
+		// the checker may have returned as the timeout expired, or given up because its context was cancelled
+		ticker.recordTimeout(ctx, timeoutCtx)
+		ticker.check.state.setRunDuration(start)
+		cancel()
+		return err
+	case <-timeoutCtx.Done():
+		ticker.recordTimeout(ctx, timeoutCtx)
+		ticker.check.state.setRunDuration(start)
+		ticker.check.abandoned.Store(true)
+		go func() {
+			defer cancel()
+			<-done
+			defer ticker.check.abandoned.Store(false)
+			// record the timeout again in case the checker overwrote the state when it returned
+			ticker.recordTimeout(ctx, timeoutCtx)
+		}()
+		return timeoutCtx.Err()
+	}
+}
+
+// recordTimeout sets the check state to CRITICAL if the provided check context has exceeded its deadline
+func (ticker *ticker) recordTimeout(ctx, timeoutCtx context.Context) {
+	if !errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
 		return
 	}
-	ticker.timeTicker.Stop()
-	close(ticker.closing)
+	message := fmt.Sprintf("check timed out after %s", ticker.check.timeout)
+	if err := ticker.check.state.Update(StatusCritical, message, 0); err != nil {
+		log.Error(ctx, "failed to update check state", err, log.Data{"external_service": ticker.check.state.Name()})
+	}
+}
+
+// stop the ticker and wait for its goroutine to return. The ticker must have been started.
+func (ticker *ticker) stop() {
+	ticker.close()
 	<-ticker.closed
 }
 
+// close stops the time ticker and marks the ticker as stopping, it is safe to call more than once
+func (ticker *ticker) close() {
+	ticker.closeOnce.Do(func() {
+		ticker.timeTicker.Stop()
+		close(ticker.closing)
+	})
+}
+
 func (ticker *ticker) isStopping() bool {
 	select {
 	case <-ticker.closing:
@@ -0,0 +1,212 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HealthEvent represents a change in the health of the checks that are watched with Watch
+type HealthEvent struct {
+	// Sequence increases by one with every event sent on the same channel, starting at 1
+	Sequence uint64
+	// Time is when the change happened
+	Time time.Time
+	// Check is the check whose state change triggered the event, or nil if the event was not triggered
+	// by a check state change, e.g. when a check is removed or when the critical timeout of a check expires
+	Check *Check
+	// OldStatus is the accumulated status of the watched checks before the change
+	OldStatus string
+	// NewStatus is the accumulated status of the watched checks after the change
+	NewStatus string
+}
+
+// watchQueueSize is the maximum number of events queued for a watch whose channel is not being received from.
+// Once it is reached, the oldest queued event is dropped to make room for the new one.
+const watchQueueSize = 1000
+
+// watch keeps track of the checks watched by a Watch call, and queues the events to send for it
+type watch struct {
+	checks   map[*Check]struct{}
+	all      bool
+	status   string
+	sequence uint64
+	cancel   context.CancelFunc
+	mutex    *sync.Mutex
+	queue    []HealthEvent
+	pending  chan struct{}
+}
+
+// Watch returns a channel of events for every change of the state of the provided checks, or of all the checks if none is provided
+func (hc *HealthCheck) Watch(ctx context.Context, checks ...*Check) <-chan HealthEvent {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &watch{
+		checks:  map[*Check]struct{}{},
+		all:     len(checks) == 0,
+		cancel:  cancel,
+		mutex:   &sync.Mutex{},
+		pending: make(chan struct{}, 1),
+	}
+	for _, check := range checks {
+		w.checks[check] = struct{}{}
+	}
+
+	hc.subsMutex.Lock()
+	hc.statusLock.Lock()
+	w.status = hc.getChecksStatus(w.getChecks(hc.Checks))
+	hc.statusLock.Unlock()
+	if hc.watches == nil {
+		hc.watches = map[*watch]struct{}{}
+	}
+	hc.watches[w] = struct{}{}
+	hc.subsMutex.Unlock()
+
+	events := make(chan HealthEvent)
+	go func() {
+		defer close(events)
+		defer func() {
+			hc.subsMutex.Lock()
+			defer hc.subsMutex.Unlock()
+			delete(hc.watches, w)
+		}()
+		w.run(ctx, events)
+	}()
+
+	return events
+}
+
+// watches returns true if the provided check is watched
+func (w *watch) watches(check *Check) bool {
+	if w.all {
+		return true
+	}
+	_, ok := w.checks[check]
+	return ok
+}
+
+// getChecks returns the watched checks, given all the checks of the health check
+func (w *watch) getChecks(all []*Check) []*Check {
+	if w.all {
+		return all
+	}
+	checks := make([]*Check, 0, len(w.checks))
+	for check := range w.checks {
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// push queues an event to be sent, without blocking, dropping the oldest queued event if the queue is full.
+// Returns true if an event has been dropped.
+func (w *watch) push(event HealthEvent) bool {
+	w.mutex.Lock()
+	dropped := len(w.queue) >= watchQueueSize
+	if dropped {
+		w.queue = w.queue[1:]
+	}
+	w.queue = append(w.queue, event)
+	w.mutex.Unlock()
+
+	select {
+	case w.pending <- struct{}{}:
+	default:
+	}
+	return dropped
+}
+
+// pop returns the oldest queued event, and false if there are none
+func (w *watch) pop() (HealthEvent, bool) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.queue) == 0 {
+		return HealthEvent{}, false
+	}
+	event := w.queue[0]
+	w.queue = w.queue[1:]
+	return event, true
+}
+
+// run sends the queued events on the provided channel, in order, until the context is done
+func (w *watch) run(ctx context.Context, events chan<- HealthEvent) {
+	for {
+		event, ok := w.pop()
+		if !ok {
+			select {
+			case <-w.pending:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case events <- event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notifyWatches queues an event for every watch affected by a change triggered by the provided check,
+// or by every watch whose status has changed if no check is provided.
+// The caller must hold the subsMutex and the statusLock.
+func (hc *HealthCheck) notifyWatches(check *Check) {
+	now := hc.getClock().Now().UTC()
+	for w := range hc.watches {
+		if check != nil && !w.watches(check) {
+			continue
+		}
+
+		status := hc.getChecksStatus(w.getChecks(hc.Checks))
+		if check == nil && status == w.status {
+			continue
+		}
+
+		w.sequence++
+		if w.push(HealthEvent{
+			Sequence:  w.sequence,
+			Time:      now,
+			Check:     check,
+			OldStatus: w.status,
+			NewStatus: status,
+		}) {
+			hc.droppedWatchEvents++
+		}
+		w.status = status
+	}
+}
+
+// getDroppedWatchEvents returns the number of events dropped because the queue of their watch was full
+func (hc *HealthCheck) getDroppedWatchEvents() uint64 {
+	hc.subsMutex.Lock()
+	defer hc.subsMutex.Unlock()
+	return hc.droppedWatchEvents
+}
+
+// removeFromWatches removes the provided checks from every watch, closing the watches that are left without checks.
+// The caller must hold the subsMutex.
+func (hc *HealthCheck) removeFromWatches(checks []*Check) {
+	for w := range hc.watches {
+		if w.all {
+			continue
+		}
+		for _, check := range checks {
+			delete(w.checks, check)
+		}
+		if len(w.checks) == 0 {
+			w.cancel()
+		}
+	}
+}
+
+// replaceInWatches replaces the old check by the new one in every watch of the old check.
+// The caller must hold the subsMutex.
+func (hc *HealthCheck) replaceInWatches(old, check *Check) {
+	for w := range hc.watches {
+		if _, ok := w.checks[old]; ok {
+			delete(w.checks, old)
+			w.checks[check] = struct{}{}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// receiveEvent returns the next event sent on the provided channel, failing if none is sent within a second
+func receiveEvent(events <-chan HealthEvent) HealthEvent {
+	select {
+	case event, ok := <-events:
+		So(ok, ShouldBeTrue)
+		return event
+	case <-time.After(time.Second):
+		So("no event was received", ShouldBeEmpty)
+		return HealthEvent{}
+	}
+}
+
+// assertClosed fails if the provided channel is not closed within a second
+func assertClosed(events <-chan HealthEvent) {
+	select {
+	case _, ok := <-events:
+		So(ok, ShouldBeFalse)
+	case <-time.After(time.Second):
+		So("the channel was not closed", ShouldBeEmpty)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	checkerFunc := func(ctx context.Context, state *CheckState) error {
+		return nil
+	}
+
+	Convey("Given a Health Check with 2 checks that are OK", t, func() {
+		hc := New(version, criticalTimeout, interval)
+		c1, err := hc.AddAndGetCheck("check 1", checkerFunc)
+		So(err, ShouldBeNil)
+		c2, err := hc.AddAndGetCheck("check 2", checkerFunc)
+		So(err, ShouldBeNil)
+		So(c1.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+		So(c2.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		Convey("When the first check is watched and its state changes several times", func() {
+			events := hc.Watch(ctx, c1)
+			So(c1.state.Update(StatusWarning, "slow", 0), ShouldBeNil)
+			So(c2.state.Update(StatusWarning, "slow", 0), ShouldBeNil)
+			So(c1.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+			So(c1.state.Update(StatusWarning, "slow", 0), ShouldBeNil)
+
+			Convey("Then an event is sent for every change of the watched check, in order", func() {
+				for i, expected := range []struct{ old, new string }{
+					{StatusOK, StatusWarning},
+					{StatusWarning, StatusOK},
+					{StatusOK, StatusWarning},
+				} {
+					event := receiveEvent(events)
+					So(event.Sequence, ShouldEqual, i+1)
+					So(event.Check, ShouldEqual, c1)
+					So(event.OldStatus, ShouldEqual, expected.old)
+					So(event.NewStatus, ShouldEqual, expected.new)
+					So(event.Time, ShouldNotBeZeroValue)
+				}
+			})
+
+			Convey("Then the channel is closed and the watch removed once the context is cancelled", func() {
+				cancel()
+				for range events {
+				}
+				hc.subsMutex.Lock()
+				defer hc.subsMutex.Unlock()
+				So(hc.watches, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When all the checks are watched", func() {
+			events := hc.Watch(ctx)
+
+			Convey("Then events are sent for the changes of any check, including checks added later", func() {
+				So(c2.state.Update(StatusWarning, "slow", 0), ShouldBeNil)
+				event := receiveEvent(events)
+				So(event.Check, ShouldEqual, c2)
+				So(event.NewStatus, ShouldEqual, StatusWarning)
+
+				c3, err := hc.AddAndGetCheck("check 3", checkerFunc)
+				So(err, ShouldBeNil)
+				So(c3.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+				event = receiveEvent(events)
+				So(event.Sequence, ShouldEqual, 2)
+				So(event.Check, ShouldEqual, c3)
+				So(event.OldStatus, ShouldEqual, StatusWarning)
+				So(event.NewStatus, ShouldEqual, StatusWarning)
+			})
+
+			Convey("Then an event is sent without a check when removing a check changes the status", func() {
+				So(c2.state.Update(StatusWarning, "slow", 0), ShouldBeNil)
+				receiveEvent(events)
+
+				So(hc.RemoveCheck(c2), ShouldBeNil)
+				event := receiveEvent(events)
+				So(event.Check, ShouldBeNil)
+				So(event.OldStatus, ShouldEqual, StatusWarning)
+				So(event.NewStatus, ShouldEqual, StatusOK)
+			})
+		})
+
+		Convey("When a check is watched and then removed", func() {
+			events := hc.Watch(ctx, c2)
+			So(hc.RemoveCheck(c2), ShouldBeNil)
+
+			Convey("Then the channel is closed", func() {
+				assertClosed(events)
+			})
+		})
+
+		Convey("When a check is watched and then replaced", func() {
+			events := hc.Watch(ctx, c2)
+			c3, err := hc.ReplaceCheck("check 2", checkerFunc)
+			So(err, ShouldBeNil)
+
+			Convey("Then an event is sent as the new check has not run yet, and events are sent for the new check", func() {
+				event := receiveEvent(events)
+				So(event.Check, ShouldBeNil)
+				So(event.OldStatus, ShouldEqual, StatusOK)
+				So(event.NewStatus, ShouldEqual, StatusWarning)
+
+				So(c3.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+				event = receiveEvent(events)
+				So(event.Check == c3, ShouldBeTrue)
+				So(event.NewStatus, ShouldEqual, StatusOK)
+			})
+		})
+
+		Convey("When events are not read for a while", func() {
+			events := hc.Watch(ctx, c1)
+			for i := 0; i < 50; i++ {
+				status := StatusWarning
+				if i%2 == 1 {
+					status = StatusOK
+				}
+				So(c1.state.Update(status, "", 0), ShouldBeNil)
+			}
+
+			Convey("Then no events are lost", func() {
+				for i := 0; i < 50; i++ {
+					So(receiveEvent(events).Sequence, ShouldEqual, i+1)
+				}
+			})
+		})
+
+		Convey("When more events are sent than can be queued while they are not read", func() {
+			events := hc.Watch(ctx, c1)
+			total := watchQueueSize + 10
+			for i := 0; i < total; i++ {
+				status := StatusWarning
+				if i%2 == 1 {
+					status = StatusOK
+				}
+				So(c1.state.Update(status, "", 0), ShouldBeNil)
+			}
+
+			Convey("Then the oldest events are dropped and counted, and the latest ones are sent in order", func() {
+				received := 0
+				var sequence uint64
+				for sequence < uint64(total) {
+					event := receiveEvent(events)
+					So(event.Sequence, ShouldBeGreaterThan, sequence)
+					sequence = event.Sequence
+					received++
+				}
+				So(received, ShouldBeLessThan, total)
+				So(hc.getDroppedWatchEvents(), ShouldEqual, total-received)
+
+				w := httptest.NewRecorder()
+				hc.MetricsHandler(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+				So(w.Body.String(), ShouldContainSubstring, "healthcheck_watch_events_dropped_total "+strconv.Itoa(total-received)+"\n")
+			})
+		})
+	})
+
+	Convey("Given a Health Check with a check that has a critical timeout", t, func() {
+		hc := New(version, criticalTimeout, interval)
+		c, err := hc.AddAndGetCheck("check", checkerFunc, WithCriticalTimeout(100*time.Millisecond))
+		So(err, ShouldBeNil)
+		So(c.state.Update(StatusOK, "ok", 0), ShouldBeNil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		Convey("When the check is watched and becomes CRITICAL", func() {
+			events := hc.Watch(ctx, c)
+			So(c.state.Update(StatusCritical, "failed", 0), ShouldBeNil)
+
+			Convey("Then an event is sent for the change, and another one without a check once the critical timeout expires", func() {
+				event := receiveEvent(events)
+				So(event.Check, ShouldEqual, c)
+				So(event.OldStatus, ShouldEqual, StatusOK)
+				So(event.NewStatus, ShouldEqual, StatusWarning)
+
+				event = receiveEvent(events)
+				So(event.Sequence, ShouldEqual, 2)
+				So(event.Check, ShouldBeNil)
+				So(event.OldStatus, ShouldEqual, StatusWarning)
+				So(event.NewStatus, ShouldEqual, StatusCritical)
+			})
+		})
+	})
+}